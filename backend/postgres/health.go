@@ -44,8 +44,19 @@ func NewHealthCheck(db *bun.DB) *HealthCheck {
 	}
 }
 
+// NewReadOnlyHealthCheck creates a new HealthCheck instance that only
+// performs the read test, e.g. for a replica.
+func NewReadOnlyHealthCheck(db *bun.DB) *HealthCheck {
+	return &HealthCheck{
+		selectQueryExecutor: db.NewRaw("SELECT 1;"),
+	}
+}
+
 // Init initializes the test table
 func (h *HealthCheck) Init(ctx context.Context) error {
+	if h.createTableQueryExecutor == nil {
+		return nil
+	}
 	_, err := h.createTableQueryExecutor.Exec(ctx)
 	return err
 }
@@ -64,6 +75,12 @@ func (h *HealthCheck) HealthCheck(ctx context.Context) servicehealthcheck.Health
 		return h.state.GetState()
 	}
 
+	if h.insertQueryExecutor == nil {
+		// read-only
+		h.state.SetHealthy()
+		return h.state.GetState()
+	}
+
 	// writecheck - add Data to configured Table
 	if _, err := h.insertQueryExecutor.Exec(ctx); err != nil {
 		h.state.SetErrorState(err)
@@ -85,6 +102,9 @@ func (h *HealthCheck) HealthCheck(ctx context.Context) servicehealthcheck.Health
 
 // CleanUp drops the test table.
 func (h *HealthCheck) CleanUp(ctx context.Context) error {
+	if h.dropTableQueryExecutor == nil {
+		return nil
+	}
 	_, err := h.dropTableQueryExecutor.Exec(ctx)
 
 	return err
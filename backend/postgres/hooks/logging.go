@@ -87,3 +87,9 @@ func determineQueryMode(qry string) queryMode {
 
 	return writeMode
 }
+
+// IsReadQuery returns true if the query only reads from the database, using
+// the same classification as the logging of queries.
+func IsReadQuery(qry string) bool {
+	return determineQueryMode(qry) == readMode
+}
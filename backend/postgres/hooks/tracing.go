@@ -9,11 +9,19 @@ import (
 
 	"github.com/getsentry/sentry-go"
 	"github.com/uptrace/bun"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/pace/bricks/maintenance/tracing"
 )
 
 var (
 	reQueryType        = regexp.MustCompile(`(\s)`)
 	reQueryTypeCleanup = regexp.MustCompile(`(?m)(\s+|\n)`)
+	// literals of queries: strings, dollar quoted strings, placeholders and
+	// numbers
+	reQueryLiteral = regexp.MustCompile(`(?i)\bE'(?:[^'\\]|\\.|'')*'|'(?:[^']|'')*'|\$\$[\s\S]*?\$\$|\$\d+|\b\d+(?:\.\d+)?(?:e[+-]?\d+)?\b`)
 )
 
 type TracingHook struct{}
@@ -23,6 +31,11 @@ func (h *TracingHook) BeforeQuery(ctx context.Context, event *bun.QueryEvent) co
 }
 
 func (h *TracingHook) AfterQuery(ctx context.Context, event *bun.QueryEvent) {
+	if tracing.OTelEnabled() {
+		afterQueryOTel(ctx, event)
+		return
+	}
+
 	span := sentry.StartSpan(ctx, "db.sql.query", sentry.WithDescription(getQueryType(event.Query)))
 	defer span.Finish()
 
@@ -43,6 +56,41 @@ func (h *TracingHook) AfterQuery(ctx context.Context, event *bun.QueryEvent) {
 	}
 }
 
+func afterQueryOTel(ctx context.Context, event *bun.QueryEvent) {
+	_, span := tracing.Tracer().Start(ctx, getQueryType(event.Query),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithTimestamp(event.StartTime),
+		trace.WithAttributes(
+			attribute.String("db.system", "postgresql"),
+			attribute.String("db.operation.name", getQueryType(event.Query)),
+			attribute.String("db.query.text", sanitizeQuery(event.Query)),
+		),
+	)
+	defer span.End()
+
+	// add error or result set info
+	if event.Err != nil {
+		span.RecordError(event.Err)
+		span.SetStatus(codes.Error, event.Err.Error())
+	} else if event.Result != nil {
+		rowsAffected, err := event.Result.RowsAffected()
+		if err == nil {
+			span.SetAttributes(attribute.Int64("db.response.affected_rows", rowsAffected))
+		}
+	}
+}
+
+// sanitizeQuery replaces the literals of the query with "?", as bun inlines
+// the query arguments, that may contain personal data.
+func sanitizeQuery(s string) string {
+	return reQueryLiteral.ReplaceAllStringFunc(s, func(literal string) string {
+		if len(literal) > 1 && literal[0] == '$' && literal[1] != '$' {
+			return literal // placeholder
+		}
+		return "?"
+	})
+}
+
 func getQueryType(s string) string {
 	s = reQueryTypeCleanup.ReplaceAllString(s, " ")
 	s = strings.TrimSpace(s)
@@ -0,0 +1,20 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package hooks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitizeQuery(t *testing.T) {
+	for query, expected := range map[string]string{
+		`SELECT "u"."id" FROM "users" AS "u" WHERE (email = 'jane@example.com') LIMIT 1`: `SELECT "u"."id" FROM "users" AS "u" WHERE (email = ?) LIMIT ?`,
+		`INSERT INTO "users" ("name", "score") VALUES ('O''Brien', 1.5e3), ('x', -42)`:   `INSERT INTO "users" ("name", "score") VALUES (?, ?), (?, -?)`,
+		`UPDATE "users" SET "note" = E'it\'s', "table1" = $$secret$$ WHERE "id" = $1`:    `UPDATE "users" SET "note" = ?, "table1" = ? WHERE "id" = $1`,
+		`SELECT pg_try_advisory_lock(4711)`:                                              `SELECT pg_try_advisory_lock(?)`,
+	} {
+		require.Equal(t, expected, sanitizeQuery(query))
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package migrate
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strconv"
+
+	"github.com/uptrace/bun"
+)
+
+const usage = `usage: migrate [-dry-run] [-table name] [up | down [steps] | status]
+
+  up      apply all pending migrations (default)
+  down    revert the given number of applied migrations (default 1)
+  status  list all migrations and whether they are applied
+`
+
+// Command implements the migrate sub-command of a control binary. The args
+// are the arguments following "migrate", see usage. The result is printed to
+// stdout.
+func Command(ctx context.Context, db *bun.DB, fsys fs.FS, args []string) error {
+	return command(ctx, db, fsys, args, os.Stdout)
+}
+
+func command(ctx context.Context, db *bun.DB, fsys fs.FS, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	flags.SetOutput(out)
+	flags.Usage = func() { fmt.Fprint(out, usage) } // nolint: errcheck
+	dryRun := flags.Bool("dry-run", false, "only print the migrations that would be applied or reverted")
+	table := flags.String("table", DefaultTable, "table the applied migrations are recorded in")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	m, err := New(db, fsys, WithTable(*table), WithDryRun(*dryRun))
+	if err != nil {
+		return err
+	}
+
+	prefix := ""
+	if *dryRun {
+		prefix = "(dry-run) "
+	}
+
+	switch flags.Arg(0) {
+	case "", "up":
+		applied, err := m.Up(ctx)
+		for _, mig := range applied {
+			fmt.Fprintf(out, "%sapplied %d_%s\n", prefix, mig.Version, mig.Name) // nolint: errcheck
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Fprintln(out, "no pending migrations") // nolint: errcheck
+		}
+		return err
+	case "down":
+		steps := 1
+		if flags.NArg() > 1 {
+			steps, err = strconv.Atoi(flags.Arg(1))
+			if err != nil || steps < 1 {
+				return fmt.Errorf("migrate: invalid number of steps %q", flags.Arg(1))
+			}
+		}
+		reverted, err := m.Down(ctx, steps)
+		for _, mig := range reverted {
+			fmt.Fprintf(out, "%sreverted %d_%s\n", prefix, mig.Version, mig.Name) // nolint: errcheck
+		}
+		return err
+	case "status":
+		status, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, s := range status {
+			applied := "pending"
+			if !s.AppliedAt.IsZero() {
+				applied = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			if s.Changed {
+				applied += " (changed)"
+			}
+			fmt.Fprintf(out, "%d_%s\t%s\n", s.Version, s.Name, applied) // nolint: errcheck
+		}
+		return nil
+	default:
+		flags.Usage()
+		return fmt.Errorf("migrate: unknown command %q", flags.Arg(0))
+	}
+}
@@ -0,0 +1,311 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+// Package migrate applies versioned SQL migrations to a postgres database.
+// The migrations are read from a fs.FS, usually embedded into the binary, see
+// Parse for the naming of the files. Every migration is applied in its own
+// transaction and recorded with its checksum in a table of the database.
+//
+// The migrator holds a postgres advisory lock while it runs, so if several
+// instances of a service start at the same time, only one of them migrates and
+// the others wait for it to finish.
+package migrate
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"time"
+
+	"github.com/uptrace/bun"
+
+	"github.com/pace/bricks/maintenance/log"
+)
+
+var (
+	// ErrChecksumMismatch is returned if an applied migration was changed
+	// afterwards.
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+	// ErrNoDownMigration is returned if a migration that has no
+	// down-migration should be reverted.
+	ErrNoDownMigration = errors.New("no down-migration")
+	// ErrUnknownVersion is returned if an applied migration that is not part
+	// of the migrations should be reverted.
+	ErrUnknownVersion = errors.New("unknown version")
+)
+
+// DefaultTable is the table the applied migrations are recorded in.
+const DefaultTable = "schema_migrations"
+
+type record struct {
+	bun.BaseModel `bun:"alias:migration"`
+
+	Version   int64     `bun:"version,pk"`
+	Name      string    `bun:"name,notnull"`
+	Checksum  string    `bun:"checksum,notnull"`
+	AppliedAt time.Time `bun:"applied_at,notnull,default:current_timestamp"`
+}
+
+// Migrator applies migrations to a database.
+type Migrator struct {
+	db         *bun.DB
+	migrations []Migration
+	table      string
+	dryRun     bool
+}
+
+// Option configures a Migrator.
+type Option func(*Migrator)
+
+// WithTable sets the table the applied migrations are recorded in, it
+// defaults to DefaultTable.
+func WithTable(table string) Option {
+	return func(m *Migrator) {
+		m.table = table
+	}
+}
+
+// WithDryRun only logs the migrations that would be applied or reverted
+// without changing the database.
+func WithDryRun(dryRun bool) Option {
+	return func(m *Migrator) {
+		m.dryRun = dryRun
+	}
+}
+
+// New returns a migrator for the migrations in the root directory of fsys,
+// see Parse.
+func New(db *bun.DB, fsys fs.FS, opts ...Option) (*Migrator, error) {
+	migrations, err := Parse(fsys)
+	if err != nil {
+		return nil, err
+	}
+	m := &Migrator{db: db, migrations: migrations, table: DefaultTable}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m, nil
+}
+
+// Status of a migration.
+type Status struct {
+	Migration
+	// AppliedAt is the time the migration was applied or the zero time if it
+	// is pending.
+	AppliedAt time.Time
+	// Changed is true if the migration was changed after it was applied.
+	Changed bool
+}
+
+// Status returns the status of all migrations ordered by version.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var status []Status
+	err := m.locked(ctx, func(conn bun.Conn) error {
+		records, err := m.records(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, mig := range m.migrations {
+			s := Status{Migration: mig}
+			if r, ok := records[mig.Version]; ok {
+				s.AppliedAt = r.AppliedAt
+				s.Changed = r.Checksum != mig.Checksum
+			}
+			status = append(status, s)
+		}
+		return nil
+	})
+	return status, err
+}
+
+// Up applies all pending migrations in the order of their version and returns
+// them. It fails without applying any migration if an applied migration was
+// changed afterwards. If a migration fails, the migrations applied before are
+// returned along with the error.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+	err := m.locked(ctx, func(conn bun.Conn) error {
+		records, err := m.records(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, mig := range m.migrations {
+			if r, ok := records[mig.Version]; ok && r.Checksum != mig.Checksum {
+				return fmt.Errorf("migrate: migration %d_%s was changed after it was applied: %w", mig.Version, mig.Name, ErrChecksumMismatch)
+			}
+		}
+
+		for _, mig := range m.migrations {
+			if _, ok := records[mig.Version]; ok {
+				continue
+			}
+			if err := m.apply(ctx, conn, mig); err != nil {
+				return err
+			}
+			applied = append(applied, mig)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down reverts the given number of applied migrations, starting with the one
+// with the highest version, and returns them. The number of steps must be
+// positive.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	if steps < 1 {
+		return nil, fmt.Errorf("migrate: invalid number of steps %d", steps)
+	}
+
+	byVersion := make(map[int64]Migration, len(m.migrations))
+	for _, mig := range m.migrations {
+		byVersion[mig.Version] = mig
+	}
+
+	var reverted []Migration
+	err := m.locked(ctx, func(conn bun.Conn) error {
+		records, err := m.records(ctx, conn)
+		if err != nil {
+			return err
+		}
+		versions := make([]int64, 0, len(records))
+		for version := range records {
+			versions = append(versions, version)
+		}
+		sort.Slice(versions, func(i, j int) bool { return versions[i] > versions[j] })
+		if steps < len(versions) {
+			versions = versions[:steps]
+		}
+
+		for _, version := range versions {
+			mig, ok := byVersion[version]
+			if !ok {
+				return fmt.Errorf("migrate: can't revert migration %d: %w", version, ErrUnknownVersion)
+			}
+			if mig.Down == "" {
+				return fmt.Errorf("migrate: can't revert migration %d_%s: %w", mig.Version, mig.Name, ErrNoDownMigration)
+			}
+			if err := m.revert(ctx, conn, mig); err != nil {
+				return err
+			}
+			reverted = append(reverted, mig)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+func (m *Migrator) apply(ctx context.Context, conn bun.Conn, mig Migration) error {
+	logger := log.Ctx(ctx).With().Int64("version", mig.Version).Str("name", mig.Name).Bool("dry_run", m.dryRun).Logger()
+	if m.dryRun {
+		logger.Info().Msg("would apply migration")
+		return nil
+	}
+
+	err := conn.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if _, err := tx.ExecContext(ctx, mig.Up); err != nil {
+			return err
+		}
+		_, err := tx.NewInsert().
+			Model(&record{Version: mig.Version, Name: mig.Name, Checksum: mig.Checksum}).
+			ModelTableExpr("?", bun.Ident(m.table)).
+			ExcludeColumn("applied_at").
+			Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("migrate: failed to apply migration %d_%s: %w", mig.Version, mig.Name, err)
+	}
+	logger.Info().Msg("applied migration")
+	return nil
+}
+
+func (m *Migrator) revert(ctx context.Context, conn bun.Conn, mig Migration) error {
+	logger := log.Ctx(ctx).With().Int64("version", mig.Version).Str("name", mig.Name).Bool("dry_run", m.dryRun).Logger()
+	if m.dryRun {
+		logger.Info().Msg("would revert migration")
+		return nil
+	}
+
+	err := conn.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if _, err := tx.ExecContext(ctx, mig.Down); err != nil {
+			return err
+		}
+		_, err := tx.NewDelete().
+			Model((*record)(nil)).
+			ModelTableExpr("? AS migration", bun.Ident(m.table)).
+			Where("version = ?", mig.Version).
+			Exec(ctx)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("migrate: failed to revert migration %d_%s: %w", mig.Version, mig.Name, err)
+	}
+	logger.Info().Msg("reverted migration")
+	return nil
+}
+
+// Returns the applied migrations by version. Creates the table of the applied
+// migrations if it doesn't exist, unless in dry-run mode.
+func (m *Migrator) records(ctx context.Context, conn bun.Conn) (map[int64]record, error) {
+	if m.dryRun {
+		var exists bool
+		err := conn.NewRaw("SELECT to_regclass(?) IS NOT NULL", m.table).Scan(ctx, &exists)
+		if err != nil {
+			return nil, fmt.Errorf("migrate: failed to check table %q: %w", m.table, err)
+		}
+		if !exists {
+			return map[int64]record{}, nil
+		}
+	} else {
+		_, err := conn.NewCreateTable().
+			Model((*record)(nil)).
+			ModelTableExpr("?", bun.Ident(m.table)).
+			IfNotExists().
+			Exec(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("migrate: failed to create table %q: %w", m.table, err)
+		}
+	}
+
+	var records []record
+	err := conn.NewSelect().
+		Model(&records).
+		ModelTableExpr("? AS migration", bun.Ident(m.table)).
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("migrate: failed to load applied migrations: %w", err)
+	}
+	byVersion := make(map[int64]record, len(records))
+	for _, r := range records {
+		byVersion[r.Version] = r
+	}
+	return byVersion, nil
+}
+
+// Runs fn on a single connection while holding the advisory lock of the
+// migration table.
+func (m *Migrator) locked(ctx context.Context, fn func(conn bun.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("migrate: failed to get connection: %w", err)
+	}
+	defer conn.Close() // nolint: errcheck
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock(hashtext(?))", m.table); err != nil {
+		return fmt.Errorf("migrate: failed to acquire lock: %w", err)
+	}
+	defer func() {
+		ctx := context.WithoutCancel(ctx)
+		if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock(hashtext(?))", m.table); err != nil {
+			log.Ctx(ctx).Warn().Err(err).Msg("migrate: failed to release lock, discarding connection")
+			// the lock is bound to the session, make sure the connection is
+			// not returned to the pool
+			_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+		}
+	}()
+
+	return fn(conn)
+}
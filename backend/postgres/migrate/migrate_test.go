@@ -0,0 +1,120 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package migrate
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pace/bricks/backend/postgres"
+	"github.com/pace/bricks/maintenance/log"
+)
+
+func TestParse(t *testing.T) {
+	migrations, err := Parse(fstest.MapFS{
+		"2_add_email.up.sql":      {Data: []byte("ALTER TABLE users ADD COLUMN email text;")},
+		"1_create_users.up.sql":   {Data: []byte("CREATE TABLE users (id int);")},
+		"1_create_users.down.sql": {Data: []byte("DROP TABLE users;")},
+		"README.md":               {Data: []byte("not a migration")},
+	})
+	require.NoError(t, err)
+	require.Len(t, migrations, 2)
+
+	require.Equal(t, int64(1), migrations[0].Version)
+	require.Equal(t, "create_users", migrations[0].Name)
+	require.Equal(t, "CREATE TABLE users (id int);", migrations[0].Up)
+	require.Equal(t, "DROP TABLE users;", migrations[0].Down)
+	require.Len(t, migrations[0].Checksum, 64)
+
+	require.Equal(t, int64(2), migrations[1].Version)
+	require.Empty(t, migrations[1].Down)
+}
+
+func TestParse_invalid(t *testing.T) {
+	cases := map[string]fstest.MapFS{
+		"invalid name": {
+			"create_users.up.sql": {},
+		},
+		"duplicate version": {
+			"1_create_users.up.sql":  {},
+			"1_create_orders.up.sql": {},
+		},
+		"down without up": {
+			"1_create_users.down.sql": {},
+		},
+	}
+	for name, fsys := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse(fsys)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestMigrator_Down_invalidSteps(t *testing.T) {
+	m, err := New(nil, fstest.MapFS{})
+	require.NoError(t, err)
+	for _, steps := range []int{0, -1} {
+		_, err := m.Down(context.Background(), steps)
+		require.Error(t, err)
+	}
+}
+
+func TestIntegrationMigrator(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	ctx := log.WithContext(context.Background())
+	db := postgres.NewDB(ctx)
+	const table = "migrate_integration_test"
+	_, err := db.ExecContext(ctx, "DROP TABLE IF EXISTS migrate_integration_test, migrate_integration_users")
+	require.NoError(t, err)
+
+	fsys := fstest.MapFS{
+		"1_create_users.up.sql":   {Data: []byte("CREATE TABLE migrate_integration_users (id int);")},
+		"1_create_users.down.sql": {Data: []byte("DROP TABLE migrate_integration_users;")},
+		"2_add_email.up.sql":      {Data: []byte("ALTER TABLE migrate_integration_users ADD COLUMN email text; SELECT '{}'::jsonb ? 'a';")},
+		"2_add_email.down.sql":    {Data: []byte("ALTER TABLE migrate_integration_users DROP COLUMN email;")},
+	}
+
+	// dry-run doesn't change the database
+	var out bytes.Buffer
+	require.NoError(t, command(ctx, db, fsys, []string{"-dry-run", "-table", table, "up"}, &out))
+	require.Equal(t, "(dry-run) applied 1_create_users\n(dry-run) applied 2_add_email\n", out.String())
+	m, err := New(db, fsys, WithTable(table))
+	require.NoError(t, err)
+	status, err := m.Status(ctx)
+	require.NoError(t, err)
+	require.True(t, status[0].AppliedAt.IsZero())
+
+	applied, err := m.Up(ctx)
+	require.NoError(t, err)
+	require.Len(t, applied, 2)
+	applied, err = m.Up(ctx)
+	require.NoError(t, err)
+	require.Empty(t, applied)
+
+	reverted, err := m.Down(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, reverted, 1)
+	require.Equal(t, int64(2), reverted[0].Version)
+	status, err = m.Status(ctx)
+	require.NoError(t, err)
+	require.False(t, status[0].AppliedAt.IsZero())
+	require.True(t, status[1].AppliedAt.IsZero())
+
+	// changed migrations are detected
+	fsys["1_create_users.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE migrate_integration_users (id bigint);")}
+	m, err = New(db, fsys, WithTable(table))
+	require.NoError(t, err)
+	_, err = m.Up(ctx)
+	require.ErrorIs(t, err, ErrChecksumMismatch)
+
+	reverted, err = m.Down(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, reverted, 1)
+}
@@ -0,0 +1,97 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package migrate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// Migration is a single versioned change of the database schema.
+type Migration struct {
+	Version int64
+	Name    string
+	// Up is the SQL that applies the migration.
+	Up string
+	// Down is the SQL that reverts the migration, it is empty if the
+	// migration can't be reverted.
+	Down string
+	// Checksum of Up, used to detect migrations that were changed after they
+	// were applied.
+	Checksum string
+}
+
+var fileNamePattern = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Parse reads the migrations from the root directory of fsys. The files must
+// be named VERSION_NAME.up.sql and VERSION_NAME.down.sql, e.g.
+// 20260101120000_create_users.up.sql. The down-migration is optional. Files
+// that don't end in .sql are ignored. The migrations are returned ordered by
+// version.
+func Parse(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("migrate: failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	downs := make(map[int64]string)
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migrate: invalid migration file name %q, expected VERSION_NAME.up.sql or VERSION_NAME.down.sql", entry.Name())
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migrate: invalid version of %q: %w", entry.Name(), err)
+		}
+		data, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("migrate: failed to read %q: %w", entry.Name(), err)
+		}
+
+		if match[3] == "down" {
+			if _, ok := downs[version]; ok {
+				return nil, fmt.Errorf("migrate: duplicate down-migration for version %d", version)
+			}
+			downs[version] = string(data)
+			continue
+		}
+		if _, ok := byVersion[version]; ok {
+			return nil, fmt.Errorf("migrate: duplicate migration for version %d", version)
+		}
+		sum := sha256.Sum256(data)
+		byVersion[version] = &Migration{
+			Version:  version,
+			Name:     match[2],
+			Up:       string(data),
+			Checksum: hex.EncodeToString(sum[:]),
+		}
+	}
+
+	for version, down := range downs {
+		m, ok := byVersion[version]
+		if !ok {
+			return nil, fmt.Errorf("migrate: down-migration for version %d has no up-migration", version)
+		}
+		m.Down = down
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
@@ -78,3 +78,33 @@ func WithWriteTimeout(writeTimeout time.Duration) ConfigOption {
 		cfg.WriteTimeout = writeTimeout
 	}
 }
+
+// WithReplicaHosts - hosts of read replicas, optionally with port
+func WithReplicaHosts(hosts ...string) ConfigOption {
+	return func(cfg *Config) {
+		cfg.ReplicaHosts = hosts
+	}
+}
+
+// WithSSLMode - one of SSLModeDisable, SSLModeRequire, SSLModeVerifyCA and
+// SSLModeVerifyFull
+func WithSSLMode(mode string) ConfigOption {
+	return func(cfg *Config) {
+		cfg.SSLMode = mode
+	}
+}
+
+// WithSSLRootCert - path to the CA bundle used to verify the server certificate
+func WithSSLRootCert(path string) ConfigOption {
+	return func(cfg *Config) {
+		cfg.SSLRootCert = path
+	}
+}
+
+// WithSSLCert - paths to the client certificate and its key
+func WithSSLCert(certPath, keyPath string) ConfigOption {
+	return func(cfg *Config) {
+		cfg.SSLCert = certPath
+		cfg.SSLKey = keyPath
+	}
+}
@@ -48,6 +48,23 @@ type Config struct {
 	// Timeout for socket writes. If reached, commands will fail
 	// with a timeout instead of blocking.
 	WriteTimeout time.Duration `env:"POSTGRES_WRITE_TIMEOUT" envDefault:"30s"`
+	// Hosts of read replicas, optionally with port. If set, read queries
+	// outside of transactions are routed to the replicas.
+	ReplicaHosts []string `env:"POSTGRES_REPLICA_HOSTS" envSeparator:","`
+	// Time after a write during which the reads of the same request are
+	// routed to the primary, to read your own writes despite replication lag
+	ReplicaPinAfterWrite time.Duration `env:"POSTGRES_REPLICA_PIN_AFTER_WRITE" envDefault:"1s"`
+	// Time a replica is not used after its connection failed
+	ReplicaFailureBackoff time.Duration `env:"POSTGRES_REPLICA_FAILURE_BACKOFF" envDefault:"10s"`
+	// SSLMode is one of disable, require, verify-ca and verify-full
+	SSLMode string `env:"POSTGRES_SSLMODE" envDefault:"disable"`
+	// Path to the CA bundle used to verify the server certificate. If
+	// empty, the system certificates are used.
+	SSLRootCert string `env:"POSTGRES_SSLROOTCERT"`
+	// Paths to the client certificate and its key, used for certificate
+	// based authentication. They are reloaded once the files changed.
+	SSLCert string `env:"POSTGRES_SSLCERT"`
+	SSLKey  string `env:"POSTGRES_SSLKEY"`
 }
 
 var cfg Config
@@ -57,6 +74,7 @@ func init() {
 	prometheus.MustRegister(hooks.MetricQueryFailed)
 	prometheus.MustRegister(hooks.MetricQueryDurationSeconds)
 	prometheus.MustRegister(hooks.MetricQueryAffectedTotal)
+	prometheus.MustRegister(metricQueryRoutedTotal)
 
 	err := env.Parse(&cfg)
 	if err != nil {
@@ -82,30 +100,40 @@ func init() {
 	servicehealthcheck.RegisterHealthCheck("postgresdefault", NewHealthCheck(NewDB(context.Background())))
 }
 
+// NewDB returns a connection pool for the database. If replica hosts are
+// configured, read queries are routed to the replicas, except for queries of
+// transactions and queries of a request that wrote to the database less than
+// POSTGRES_REPLICA_PIN_AFTER_WRITE ago. A health check is registered for
+// every replica.
 func NewDB(ctx context.Context, options ...ConfigOption) *bun.DB {
 	for _, opt := range options {
 		opt(&cfg)
 	}
 
-	connector := pgdriver.NewConnector(
-		pgdriver.WithAddr(net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))),
-		pgdriver.WithApplicationName(cfg.ApplicationName),
-		pgdriver.WithDatabase(cfg.Database),
-		pgdriver.WithDialTimeout(cfg.DialTimeout),
-		pgdriver.WithPassword(cfg.Password),
-		pgdriver.WithReadTimeout(cfg.ReadTimeout),
-		pgdriver.WithUser(cfg.User),
-		pgdriver.WithWriteTimeout(cfg.WriteTimeout),
-		pgdriver.WithInsecure(true),
-	)
+	certs := newCertReloader()
+	connector, err := newConnector(net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)), certs)
+	if err != nil {
+		log.Ctx(ctx).Fatal().Err(err).Msg("Invalid PostgreSQL configuration")
+	}
 
 	sqldb := sql.OpenDB(connector)
-	db := bun.NewDB(sqldb, pgdialect.New())
+
+	var router *replicaRouter
+	var dbOpts []bun.DBOption
+	if len(cfg.ReplicaHosts) > 0 {
+		router, err = newReplicaRouter(ctx, sqldb, connector.Config().Addr, cfg.ReplicaHosts, certs)
+		if err != nil {
+			log.Ctx(ctx).Fatal().Err(err).Msg("Invalid PostgreSQL replica configuration")
+		}
+		dbOpts = append(dbOpts, bun.WithConnResolver(router))
+	}
+	db := bun.NewDB(sqldb, pgdialect.New(), dbOpts...)
 
 	log.Ctx(ctx).Info().Str("addr", connector.Config().Addr).
 		Str("user", connector.Config().User).
 		Str("database", connector.Config().Database).
 		Str("as", connector.Config().AppName).
+		Str("sslmode", cfg.SSLMode).
 		Msg("PostgreSQL connection pool created")
 
 	// Add hooks
@@ -118,5 +146,31 @@ func NewDB(ctx context.Context, options ...ConfigOption) *bun.DB {
 		log.Ctx(ctx).Warn().Msg("Connection pool has logging queries disabled completely")
 	}
 
+	if router != nil {
+		db.AddQueryHook(&pinHook{router: router})
+	}
+
 	return db
 }
+
+func newConnector(addr string, certs *certReloader) (*pgdriver.Connector, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig, err := newTLSConfig(host, certs)
+	if err != nil {
+		return nil, err
+	}
+	return pgdriver.NewConnector(
+		pgdriver.WithAddr(addr),
+		pgdriver.WithApplicationName(cfg.ApplicationName),
+		pgdriver.WithDatabase(cfg.Database),
+		pgdriver.WithDialTimeout(cfg.DialTimeout),
+		pgdriver.WithPassword(cfg.Password),
+		pgdriver.WithReadTimeout(cfg.ReadTimeout),
+		pgdriver.WithUser(cfg.User),
+		pgdriver.WithWriteTimeout(cfg.WriteTimeout),
+		pgdriver.WithTLSConfig(tlsConfig),
+	), nil
+}
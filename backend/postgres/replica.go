@@ -0,0 +1,281 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net"
+	"regexp"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+
+	"github.com/pace/bricks/backend/postgres/hooks"
+	"github.com/pace/bricks/maintenance/health/servicehealthcheck"
+	"github.com/pace/bricks/maintenance/log"
+)
+
+const (
+	rolePrimary = "primary"
+	roleReplica = "replica"
+)
+
+var metricQueryRoutedTotal = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "pace_postgres_query_routed_total",
+		Help: "Collects stats about the number of postgres queries routed to the primary or a replica",
+	},
+	[]string{"database", "role"},
+)
+
+// Read queries that must run on the primary anyway: locking clauses and
+// functions with side effects, that fail on a hot standby or lock the wrong
+// node.
+var primaryOnlyRead = regexp.MustCompile(`(?i)\bFOR\s+(UPDATE|NO\s+KEY\s+UPDATE|SHARE|KEY\s+SHARE)\b|` +
+	`\b(nextval|setval|currval|lastval|pg_(try_)?advisory_\w+|pg_notify|txid_current\w*|pg_current_xact_id\w*|set_config|pg_cancel_backend|pg_terminate_backend)\s*\(`)
+
+type (
+	primaryKey  struct{}
+	pinScopeKey struct{}
+)
+
+// pinScope holds the end of the window in which the reads of its context
+// are pinned to the primary
+type pinScope struct {
+	until atomic.Int64 // unix nanoseconds
+}
+
+// ContextWithPrimary returns a context whose queries always use the
+// primary, e.g. for reads that must not be stale.
+func ContextWithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// ContextWithPinScope returns a context in which the reads are pinned to the
+// primary for POSTGRES_REPLICA_PIN_AFTER_WRITE after a write with the
+// context, so that they read their own writes. Contexts of requests are
+// pinned by their request ID, other contexts like the ones of routines or
+// queue consumers need a pin scope.
+func ContextWithPinScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, pinScopeKey{}, &pinScope{})
+}
+
+// replica health checks that are registered already, by address
+var registeredReplicas sync.Map
+
+type replica struct {
+	addr     string
+	db       *sql.DB
+	failedAt atomic.Int64 // unix nanoseconds of the last connection failure
+}
+
+func (r *replica) healthy() bool {
+	return time.Since(time.Unix(0, r.failedAt.Load())) > cfg.ReplicaFailureBackoff
+}
+
+// replicaRouter is a bun.ConnResolver that routes read queries to the
+// replicas and all other queries to the primary. Queries of transactions are
+// not resolved by bun and always use the primary.
+type replicaRouter struct {
+	primary     *sql.DB
+	primaryAddr string
+	database    string
+	replicas    []*replica
+	next        atomic.Uint64
+
+	// end of the window in which reads of a request are pinned to the
+	// primary, by request ID
+	pinsMx   sync.Mutex
+	pins     map[string]time.Time
+	prunedAt time.Time
+}
+
+// Creates a pool for every replica host and registers a health check for it.
+// The hosts may contain a port, otherwise the port of the primary is used.
+func newReplicaRouter(ctx context.Context, primary *sql.DB, primaryAddr string, hosts []string, certs *certReloader) (*replicaRouter, error) {
+	r := &replicaRouter{
+		primary:     primary,
+		primaryAddr: primaryAddr,
+		database:    cfg.Database,
+		pins:        make(map[string]time.Time),
+	}
+	for _, host := range hosts {
+		addr := host
+		if _, _, err := net.SplitHostPort(host); err != nil {
+			addr = net.JoinHostPort(host, strconv.Itoa(cfg.Port))
+		}
+		connector, err := newConnector(addr, certs)
+		if err != nil {
+			return nil, err
+		}
+		rep := &replica{addr: addr, db: sql.OpenDB(connector)}
+		r.replicas = append(r.replicas, rep)
+
+		log.Ctx(ctx).Info().Str("addr", addr).Msg("PostgreSQL replica connection pool created")
+
+		if _, loaded := registeredReplicas.LoadOrStore(addr, true); !loaded {
+			servicehealthcheck.RegisterHealthCheck("postgresreplica:"+addr,
+				NewReadOnlyHealthCheck(bun.NewDB(rep.db, pgdialect.New())))
+		}
+	}
+	return r, nil
+}
+
+// ResolveConn implements bun.ConnResolver. The router itself decides on the
+// connection per query, because only then the context is known.
+func (r *replicaRouter) ResolveConn(bun.Query) bun.IConn {
+	return r
+}
+
+// Close implements bun.ConnResolver.
+func (r *replicaRouter) Close() error {
+	var errs []error
+	for _, rep := range r.replicas {
+		errs = append(errs, rep.db.Close())
+	}
+	return errors.Join(errs...)
+}
+
+func (r *replicaRouter) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	if rep := r.replicaFor(ctx, query); rep != nil {
+		rows, err := rep.db.QueryContext(ctx, query, args...)
+		if !r.failedOver(rep, err) {
+			return rows, err
+		}
+	}
+	r.count(r.primaryAddr, rolePrimary)
+	return r.primary.QueryContext(ctx, query, args...)
+}
+
+func (r *replicaRouter) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	if rep := r.replicaFor(ctx, query); rep != nil {
+		res, err := rep.db.ExecContext(ctx, query, args...)
+		if !r.failedOver(rep, err) {
+			return res, err
+		}
+	}
+	r.count(r.primaryAddr, rolePrimary)
+	return r.primary.ExecContext(ctx, query, args...)
+}
+
+func (r *replicaRouter) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	if rep := r.replicaFor(ctx, query); rep != nil {
+		row := rep.db.QueryRowContext(ctx, query, args...)
+		if !r.failedOver(rep, row.Err()) {
+			return row
+		}
+	}
+	r.count(r.primaryAddr, rolePrimary)
+	return r.primary.QueryRowContext(ctx, query, args...)
+}
+
+// Returns the replica to run the query on, or nil if the query must run on
+// the primary. The replicas are used round-robin, unhealthy ones are skipped.
+func (r *replicaRouter) replicaFor(ctx context.Context, query string) *replica {
+	if !replicaSafe(query) || r.pinned(ctx) {
+		return nil
+	}
+	if primary, _ := ctx.Value(primaryKey{}).(bool); primary {
+		return nil
+	}
+	for range r.replicas {
+		rep := r.replicas[r.next.Add(1)%uint64(len(r.replicas))]
+		if rep.healthy() {
+			return rep
+		}
+	}
+	return nil
+}
+
+// Returns true if the query only reads and may run on a replica
+func replicaSafe(query string) bool {
+	return hooks.IsReadQuery(query) && !primaryOnlyRead.MatchString(query)
+}
+
+// Returns true if the query failed on the replica because of the connection
+// and should be run on the primary instead. The replica is marked as
+// unhealthy then. Otherwise the query is counted for the replica.
+func (r *replicaRouter) failedOver(rep *replica, err error) bool {
+	if err != nil && IsErrConnectionFailed(err) {
+		rep.failedAt.Store(time.Now().UnixNano())
+		log.Logger().Warn().Err(err).Str("addr", rep.addr).Msg("PostgreSQL replica failed, using primary")
+		return true
+	}
+	r.count(rep.addr, roleReplica)
+	return false
+}
+
+func (r *replicaRouter) count(addr, role string) {
+	metricQueryRoutedTotal.With(prometheus.Labels{
+		"database": addr + "/" + r.database,
+		"role":     role,
+	}).Inc()
+}
+
+// Returns true if reads of the context must use the primary, because the
+// pin scope or request of the context wrote to the database recently.
+func (r *replicaRouter) pinned(ctx context.Context) bool {
+	if scope, ok := ctx.Value(pinScopeKey{}).(*pinScope); ok {
+		return time.Now().UnixNano() < scope.until.Load()
+	}
+	reqID := log.RequestIDFromContext(ctx)
+	if reqID == "" {
+		return false
+	}
+	r.pinsMx.Lock()
+	defer r.pinsMx.Unlock()
+	return time.Now().Before(r.pins[reqID])
+}
+
+// Pins the reads of the pin scope or request of the context to the primary
+// for the configured window.
+func (r *replicaRouter) pin(ctx context.Context) {
+	if cfg.ReplicaPinAfterWrite <= 0 {
+		return
+	}
+	now := time.Now()
+	if scope, ok := ctx.Value(pinScopeKey{}).(*pinScope); ok {
+		scope.until.Store(now.Add(cfg.ReplicaPinAfterWrite).UnixNano())
+		return
+	}
+	reqID := log.RequestIDFromContext(ctx)
+	if reqID == "" {
+		return
+	}
+	r.pinsMx.Lock()
+	defer r.pinsMx.Unlock()
+	r.pins[reqID] = now.Add(cfg.ReplicaPinAfterWrite)
+
+	// remove expired pins from time to time
+	if now.Sub(r.prunedAt) > cfg.ReplicaPinAfterWrite {
+		for id, until := range r.pins {
+			if now.After(until) {
+				delete(r.pins, id)
+			}
+		}
+		r.prunedAt = now
+	}
+}
+
+// pinHook pins the request to the primary after successful writes, including
+// writes in transactions.
+type pinHook struct {
+	router *replicaRouter
+}
+
+func (h *pinHook) BeforeQuery(ctx context.Context, event *bun.QueryEvent) context.Context {
+	return ctx
+}
+
+func (h *pinHook) AfterQuery(ctx context.Context, event *bun.QueryEvent) {
+	if event.Err == nil && !replicaSafe(event.Query) {
+		h.router.pin(ctx)
+	}
+}
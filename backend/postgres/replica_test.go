@@ -0,0 +1,92 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rs/xid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pace/bricks/maintenance/log"
+	"github.com/pace/bricks/maintenance/log/hlog"
+)
+
+func TestReplicaRouter_replicaFor(t *testing.T) {
+	oldCfg := cfg
+	t.Cleanup(func() { cfg = oldCfg })
+	cfg.ReplicaPinAfterWrite = time.Minute
+	cfg.ReplicaFailureBackoff = time.Minute
+
+	r := &replicaRouter{
+		replicas: []*replica{{addr: "replica-1:5432"}, {addr: "replica-2:5432"}},
+		pins:     make(map[string]time.Time),
+	}
+	ctx := hlog.WithValue(context.Background(), xid.New())
+
+	// writes use the primary, reads are distributed round-robin
+	require.Nil(t, r.replicaFor(ctx, "INSERT INTO users VALUES (1)"))
+	first := r.replicaFor(ctx, "SELECT * FROM users")
+	second := r.replicaFor(ctx, "  select * from users")
+	require.NotNil(t, first)
+	require.NotNil(t, second)
+	require.NotEqual(t, first.addr, second.addr)
+
+	// unhealthy replicas are skipped
+	first.failedAt.Store(time.Now().UnixNano())
+	require.Equal(t, second, r.replicaFor(ctx, "SELECT 1"))
+	require.Equal(t, second, r.replicaFor(ctx, "SELECT 1"))
+	second.failedAt.Store(time.Now().UnixNano())
+	require.Nil(t, r.replicaFor(ctx, "SELECT 1"))
+	first.failedAt.Store(0)
+	second.failedAt.Store(0)
+
+	// reads of a request are pinned to the primary after a write
+	r.pin(ctx)
+	require.Nil(t, r.replicaFor(ctx, "SELECT 1"))
+	otherCtx := hlog.WithValue(context.Background(), xid.New())
+	require.NotNil(t, r.replicaFor(otherCtx, "SELECT 1"))
+
+	// pins expire
+	r.pins[log.RequestIDFromContext(ctx)] = time.Now().Add(-time.Second)
+	require.NotNil(t, r.replicaFor(ctx, "SELECT 1"))
+}
+
+func TestReplicaRouter_primaryOnly(t *testing.T) {
+	oldCfg := cfg
+	t.Cleanup(func() { cfg = oldCfg })
+	cfg.ReplicaPinAfterWrite = time.Minute
+
+	r := &replicaRouter{
+		replicas: []*replica{{addr: "replica-1:5432"}},
+		pins:     make(map[string]time.Time),
+	}
+	ctx := context.Background()
+
+	for _, query := range []string{
+		"SELECT * FROM users WHERE id = 1 FOR UPDATE",
+		"select * from users for no key update skip locked",
+		"SELECT * FROM users FOR SHARE",
+		"SELECT pg_advisory_lock(42)",
+		"SELECT pg_try_advisory_xact_lock(42)",
+		"SELECT nextval('users_id_seq')",
+		"SELECT pg_notify('channel', 'payload')",
+	} {
+		require.Nil(t, r.replicaFor(ctx, query), query)
+	}
+	require.NotNil(t, r.replicaFor(ctx, "SELECT * FROM users WHERE updated_for > now() ORDER BY sequence"))
+
+	// queries of primary contexts always use the primary
+	require.Nil(t, r.replicaFor(ContextWithPrimary(ctx), "SELECT 1"))
+
+	// contexts without request are pinned within a pin scope
+	r.pin(ctx)
+	require.NotNil(t, r.replicaFor(ctx, "SELECT 1"))
+	scoped := ContextWithPinScope(ctx)
+	require.NotNil(t, r.replicaFor(scoped, "SELECT 1"))
+	r.pin(scoped)
+	require.Nil(t, r.replicaFor(scoped, "SELECT 1"))
+	require.NotNil(t, r.replicaFor(ContextWithPinScope(ctx), "SELECT 1"))
+}
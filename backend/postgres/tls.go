@@ -0,0 +1,171 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package postgres
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// SSL modes supported by POSTGRES_SSLMODE, see
+// https://www.postgresql.org/docs/current/libpq-ssl.html#LIBPQ-SSL-PROTECTION
+const (
+	SSLModeDisable    = "disable"
+	SSLModeRequire    = "require"
+	SSLModeVerifyCA   = "verify-ca"
+	SSLModeVerifyFull = "verify-full"
+)
+
+// Returns the TLS config for connections to the host, or nil if TLS is
+// disabled. Like libpq, require behaves like verify-ca if a root certificate
+// is configured. Certificates are read from disk again once they changed, so
+// that rotated certificates are used for new connections.
+func newTLSConfig(host string, certs *certReloader) (*tls.Config, error) {
+	mode := cfg.SSLMode
+	if mode == SSLModeDisable {
+		return nil, nil
+	}
+	if mode == SSLModeRequire && cfg.SSLRootCert != "" {
+		mode = SSLModeVerifyCA
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName: host,
+		MinVersion: tls.VersionTLS12,
+		// verification is done in VerifyConnection, to use the current root
+		// certificates and to support verify-ca
+		InsecureSkipVerify: true, // nolint: gosec
+	}
+	if cfg.SSLCert != "" || cfg.SSLKey != "" {
+		if cfg.SSLCert == "" || cfg.SSLKey == "" {
+			return nil, errors.New("postgres: both POSTGRES_SSLCERT and POSTGRES_SSLKEY must be set")
+		}
+		tlsConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return certs.clientCertificate()
+		}
+	}
+
+	switch mode {
+	case SSLModeRequire:
+		return tlsConfig, nil
+	case SSLModeVerifyCA, SSLModeVerifyFull:
+		tlsConfig.VerifyConnection = func(cs tls.ConnectionState) error {
+			return certs.verify(cs, mode == SSLModeVerifyFull)
+		}
+		return tlsConfig, nil
+	default:
+		return nil, fmt.Errorf("postgres: sslmode %q is not supported", cfg.SSLMode)
+	}
+}
+
+// certReloader loads the certificates from disk and reloads them once the
+// files were modified.
+type certReloader struct {
+	rootCertPath, certPath, keyPath string
+
+	mx          sync.Mutex
+	rootCAs     *x509.CertPool
+	rootModTime time.Time
+	cert        *tls.Certificate
+	certModTime time.Time
+	keyModTime  time.Time
+}
+
+func newCertReloader() *certReloader {
+	return &certReloader{
+		rootCertPath: cfg.SSLRootCert,
+		certPath:     cfg.SSLCert,
+		keyPath:      cfg.SSLKey,
+	}
+}
+
+func (r *certReloader) clientCertificate() (*tls.Certificate, error) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	certModTime, err := modTime(r.certPath)
+	if err != nil {
+		return nil, err
+	}
+	keyModTime, err := modTime(r.keyPath)
+	if err != nil {
+		return nil, err
+	}
+	if r.cert != nil && certModTime.Equal(r.certModTime) && keyModTime.Equal(r.keyModTime) {
+		return r.cert, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certPath, r.keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: failed to load client certificate: %w", err)
+	}
+	r.cert, r.certModTime, r.keyModTime = &cert, certModTime, keyModTime
+	return r.cert, nil
+}
+
+// Returns the pool of root certificates, or nil to use the system pool if no
+// root certificate is configured.
+func (r *certReloader) rootCertPool() (*x509.CertPool, error) {
+	if r.rootCertPath == "" {
+		return nil, nil
+	}
+
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	rootModTime, err := modTime(r.rootCertPath)
+	if err != nil {
+		return nil, err
+	}
+	if r.rootCAs != nil && rootModTime.Equal(r.rootModTime) {
+		return r.rootCAs, nil
+	}
+
+	data, err := os.ReadFile(r.rootCertPath)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: failed to read root certificate: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("postgres: no certificate found in %q", r.rootCertPath)
+	}
+	r.rootCAs, r.rootModTime = pool, rootModTime
+	return r.rootCAs, nil
+}
+
+// Verifies the certificate chain of the server and, if verifyHost is true,
+// that the certificate is valid for the host.
+func (r *certReloader) verify(cs tls.ConnectionState, verifyHost bool) error {
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("postgres: server sent no certificate")
+	}
+	roots, err := r.rootCertPool()
+	if err != nil {
+		return err
+	}
+	opts := x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: x509.NewCertPool(),
+	}
+	if verifyHost {
+		opts.DNSName = cs.ServerName
+	}
+	for _, cert := range cs.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+	_, err = cs.PeerCertificates[0].Verify(opts)
+	return err
+}
+
+func modTime(path string) (time.Time, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("postgres: %w", err)
+	}
+	return info.ModTime(), nil
+}
@@ -0,0 +1,164 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package postgres
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCert(t *testing.T, cn string, parent *testCert) *testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	signer, signerKey := tmpl, key
+	if parent == nil {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+		tmpl.KeyUsage = x509.KeyUsageCertSign
+	} else {
+		tmpl.DNSNames = []string{cn}
+		tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCert{cert: cert, key: key}
+}
+
+func (c *testCert) writeCert(t *testing.T, path string) {
+	t.Helper()
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.cert.Raw})
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+}
+
+func (c *testCert) writeKey(t *testing.T, path string) {
+	t.Helper()
+	der, err := x509.MarshalECPrivateKey(c.key)
+	require.NoError(t, err)
+	data := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	oldCfg := cfg
+	t.Cleanup(func() { cfg = oldCfg })
+
+	dir := t.TempDir()
+	ca := newTestCert(t, "ca", nil)
+	ca.writeCert(t, filepath.Join(dir, "ca.pem"))
+	otherCA := newTestCert(t, "other-ca", nil)
+	server := newTestCert(t, "db.example.com", ca)
+	untrusted := newTestCert(t, "db.example.com", otherCA)
+
+	connState := func(host string, cert *testCert) tls.ConnectionState {
+		return tls.ConnectionState{ServerName: host, PeerCertificates: []*x509.Certificate{cert.cert}}
+	}
+
+	cfg.SSLMode = SSLModeDisable
+	tlsConfig, err := newTLSConfig("db.example.com", newCertReloader())
+	require.NoError(t, err)
+	require.Nil(t, tlsConfig)
+
+	cfg.SSLMode = SSLModeRequire
+	tlsConfig, err = newTLSConfig("db.example.com", newCertReloader())
+	require.NoError(t, err)
+	require.Nil(t, tlsConfig.VerifyConnection)
+
+	cfg.SSLMode = SSLModeVerifyCA
+	cfg.SSLRootCert = filepath.Join(dir, "ca.pem")
+	tlsConfig, err = newTLSConfig("other.example.com", newCertReloader())
+	require.NoError(t, err)
+	require.NoError(t, tlsConfig.VerifyConnection(connState("other.example.com", server)))
+	require.Error(t, tlsConfig.VerifyConnection(connState("other.example.com", untrusted)))
+
+	// require verifies the chain like verify-ca if a root certificate is set
+	cfg.SSLMode = SSLModeRequire
+	tlsConfig, err = newTLSConfig("db.example.com", newCertReloader())
+	require.NoError(t, err)
+	require.Error(t, tlsConfig.VerifyConnection(connState("db.example.com", untrusted)))
+
+	cfg.SSLMode = SSLModeVerifyFull
+	tlsConfig, err = newTLSConfig("db.example.com", newCertReloader())
+	require.NoError(t, err)
+	require.NoError(t, tlsConfig.VerifyConnection(connState("db.example.com", server)))
+	require.Error(t, tlsConfig.VerifyConnection(connState("other.example.com", server)))
+
+	cfg.SSLCert = filepath.Join(dir, "client.pem")
+	_, err = newTLSConfig("db.example.com", newCertReloader())
+	require.Error(t, err, "key is missing")
+
+	cfg.SSLCert = ""
+	cfg.SSLMode = "prefer"
+	_, err = newTLSConfig("db.example.com", newCertReloader())
+	require.Error(t, err)
+}
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "ca", nil)
+	ca.writeCert(t, filepath.Join(dir, "ca.pem"))
+
+	certs := &certReloader{
+		rootCertPath: filepath.Join(dir, "ca.pem"),
+		certPath:     filepath.Join(dir, "client.pem"),
+		keyPath:      filepath.Join(dir, "client.key"),
+	}
+	_, err := certs.clientCertificate()
+	require.Error(t, err)
+
+	first := newTestCert(t, "client", ca)
+	first.writeCert(t, certs.certPath)
+	first.writeKey(t, certs.keyPath)
+	cert, err := certs.clientCertificate()
+	require.NoError(t, err)
+	require.Equal(t, first.cert.Raw, cert.Certificate[0])
+	cached, err := certs.clientCertificate()
+	require.NoError(t, err)
+	require.Same(t, cert, cached)
+
+	// rotated certificates are loaded once the files changed
+	second := newTestCert(t, "client", ca)
+	second.writeCert(t, certs.certPath)
+	second.writeKey(t, certs.keyPath)
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(certs.certPath, later, later))
+	require.NoError(t, os.Chtimes(certs.keyPath, later, later))
+	cert, err = certs.clientCertificate()
+	require.NoError(t, err)
+	require.Equal(t, second.cert.Raw, cert.Certificate[0])
+
+	pool, err := certs.rootCertPool()
+	require.NoError(t, err)
+	otherCA := newTestCert(t, "other-ca", nil)
+	otherCA.writeCert(t, certs.rootCertPath)
+	require.NoError(t, os.Chtimes(certs.rootCertPath, later, later))
+	reloaded, err := certs.rootCertPool()
+	require.NoError(t, err)
+	require.False(t, pool.Equal(reloaded))
+}
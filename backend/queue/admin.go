@@ -0,0 +1,235 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package queue
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+
+	"github.com/adjust/rmq/v5"
+	"github.com/gorilla/mux"
+
+	"github.com/pace/bricks/maintenance/log"
+	"github.com/pace/bricks/pkg/redact"
+)
+
+const (
+	adminDefaultLimit = 50
+	adminMaxLimit     = 1000
+)
+
+// AdminHandler returns a handler to inspect the queues and to replay or purge
+// rejected deliveries. Mount it on the router returned by http.Router():
+//
+//	r.PathPrefix("/debug/queues").Handler(queue.AdminHandler())
+//
+// The handler serves the following endpoints:
+//
+//	GET    /debug/queues                          open queues with their counts
+//	GET    /debug/queues/{queue}/rejected         rejected deliveries, paged using offset and limit
+//	POST   /debug/queues/{queue}/rejected/return  returns rejected deliveries to ready, at most count if given
+//	DELETE /debug/queues/{queue}/rejected         purges all rejected deliveries
+//
+// Unknown queues are answered with 404 when returning or purging rejected
+// deliveries, so that they are not created. Payloads and headers of rejected
+// deliveries are redacted using redact.Default. Like the other /debug
+// endpoints, the handler is not authenticated and must not be exposed
+// publicly.
+func AdminHandler() http.Handler {
+	r := mux.NewRouter()
+	s := r.PathPrefix("/debug/queues").Subrouter()
+	s.HandleFunc("", listQueues).Methods(http.MethodGet)
+	s.HandleFunc("/{queue}/rejected", listRejected).Methods(http.MethodGet)
+	s.HandleFunc("/{queue}/rejected/return", returnRejected).Methods(http.MethodPost)
+	s.HandleFunc("/{queue}/rejected", purgeRejected).Methods(http.MethodDelete)
+	return r
+}
+
+type adminQueue struct {
+	Name        string `json:"name"`
+	Ready       int64  `json:"ready"`
+	Rejected    int64  `json:"rejected"`
+	Unacked     int64  `json:"unacked"`
+	Consumers   int64  `json:"consumers"`
+	Connections int64  `json:"connections"`
+}
+
+type adminDelivery struct {
+	Header  http.Header `json:"header,omitempty"`
+	Payload string      `json:"payload"`
+}
+
+type adminRejected struct {
+	Total      int64           `json:"total"`
+	Offset     int64           `json:"offset"`
+	Deliveries []adminDelivery `json:"deliveries"`
+}
+
+type adminCount struct {
+	Count int64 `json:"count"`
+}
+
+func listQueues(w http.ResponseWriter, r *http.Request) {
+	if err := initDefault(); err != nil {
+		writeAdminError(w, r, http.StatusServiceUnavailable, err)
+		return
+	}
+	names, err := rmqConnection.GetOpenQueues()
+	if err != nil {
+		writeAdminError(w, r, http.StatusInternalServerError, fmt.Errorf("could not get open queues: %w", err))
+		return
+	}
+	stats, err := rmqConnection.CollectStats(names)
+	if err != nil {
+		writeAdminError(w, r, http.StatusInternalServerError, fmt.Errorf("could not collect stats: %w", err))
+		return
+	}
+	sort.Strings(names)
+
+	queues := make([]adminQueue, 0, len(names))
+	for _, name := range names {
+		stat := stats.QueueStats[name]
+		queues = append(queues, adminQueue{
+			Name:        name,
+			Ready:       stat.ReadyCount,
+			Rejected:    stat.RejectedCount,
+			Unacked:     stat.UnackedCount(),
+			Consumers:   stat.ConsumerCount(),
+			Connections: stat.ConnectionCount(),
+		})
+	}
+	writeAdminJSON(w, r, queues)
+}
+
+func listRejected(w http.ResponseWriter, r *http.Request) {
+	offset, err := queryInt(r, "offset", 0)
+	if err != nil || offset < 0 {
+		writeAdminError(w, r, http.StatusBadRequest, fmt.Errorf("invalid offset"))
+		return
+	}
+	limit, err := queryInt(r, "limit", adminDefaultLimit)
+	if err != nil || limit < 1 || limit > adminMaxLimit {
+		writeAdminError(w, r, http.StatusBadRequest, fmt.Errorf("limit must be between 1 and %d", adminMaxLimit))
+		return
+	}
+	if err := initDefault(); err != nil {
+		writeAdminError(w, r, http.StatusServiceUnavailable, err)
+		return
+	}
+
+	ctx := r.Context()
+	key := rejectedKey(mux.Vars(r)["queue"])
+	total, err := redisClient.LLen(ctx, key).Result()
+	if err != nil {
+		writeAdminError(w, r, http.StatusInternalServerError, fmt.Errorf("could not count rejected deliveries: %w", err))
+		return
+	}
+	payloads, err := redisClient.LRange(ctx, key, offset, offset+limit-1).Result()
+	if err != nil {
+		writeAdminError(w, r, http.StatusInternalServerError, fmt.Errorf("could not load rejected deliveries: %w", err))
+		return
+	}
+
+	res := adminRejected{Total: total, Offset: offset, Deliveries: make([]adminDelivery, 0, len(payloads))}
+	for _, p := range payloads {
+		header, payload, err := rmq.ExtractHeaderAndPayload(p)
+		if err != nil {
+			payload = p
+		}
+		for _, values := range header {
+			for i := range values {
+				values[i] = redact.Default.Mask(values[i])
+			}
+		}
+		res.Deliveries = append(res.Deliveries, adminDelivery{Header: header, Payload: redact.Default.Mask(payload)})
+	}
+	writeAdminJSON(w, r, res)
+}
+
+func returnRejected(w http.ResponseWriter, r *http.Request) {
+	count, err := queryInt(r, "count", -1)
+	if err != nil || count == 0 || count < -1 {
+		writeAdminError(w, r, http.StatusBadRequest, fmt.Errorf("invalid count"))
+		return
+	}
+	queue, ok := openAdminQueue(w, r)
+	if !ok {
+		return
+	}
+	n, err := queue.ReturnRejected(count)
+	if err != nil {
+		writeAdminError(w, r, http.StatusInternalServerError, fmt.Errorf("could not return rejected deliveries: %w", err))
+		return
+	}
+	log.Ctx(r.Context()).Info().Str("queue", mux.Vars(r)["queue"]).Int64("count", n).Msg("returned rejected deliveries")
+	writeAdminJSON(w, r, adminCount{Count: n})
+}
+
+func purgeRejected(w http.ResponseWriter, r *http.Request) {
+	queue, ok := openAdminQueue(w, r)
+	if !ok {
+		return
+	}
+	n, err := queue.PurgeRejected()
+	if err != nil {
+		writeAdminError(w, r, http.StatusInternalServerError, fmt.Errorf("could not purge rejected deliveries: %w", err))
+		return
+	}
+	log.Ctx(r.Context()).Info().Str("queue", mux.Vars(r)["queue"]).Int64("count", n).Msg("purged rejected deliveries")
+	writeAdminJSON(w, r, adminCount{Count: n})
+}
+
+// Opens the queue of the request, if it is an open queue. Otherwise the error
+// is written, e.g. 404 for unknown queues, and false is returned.
+func openAdminQueue(w http.ResponseWriter, r *http.Request) (rmq.Queue, bool) {
+	if err := initDefault(); err != nil {
+		writeAdminError(w, r, http.StatusServiceUnavailable, err)
+		return nil, false
+	}
+	name := mux.Vars(r)["queue"]
+	names, err := rmqConnection.GetOpenQueues()
+	if err != nil {
+		writeAdminError(w, r, http.StatusInternalServerError, fmt.Errorf("could not get open queues: %w", err))
+		return nil, false
+	}
+	if !slices.Contains(names, name) {
+		writeAdminError(w, r, http.StatusNotFound, fmt.Errorf("unknown queue %q", name))
+		return nil, false
+	}
+	queue, err := rmqConnection.OpenQueue(name)
+	if err != nil {
+		writeAdminError(w, r, http.StatusServiceUnavailable, err)
+		return nil, false
+	}
+	return queue, true
+}
+
+// Key of the list of rejected deliveries of the queue, see rmq's redis_keys.go.
+func rejectedKey(queue string) string {
+	return "rmq::queue::[" + queue + "]::rejected"
+}
+
+// Returns the query parameter as integer or the default if it is missing.
+func queryInt(r *http.Request, name string, def int64) (int64, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return def, nil
+	}
+	return strconv.ParseInt(v, 10, 64)
+}
+
+func writeAdminJSON(w http.ResponseWriter, r *http.Request, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Ctx(r.Context()).Warn().Err(err).Msg("queue admin: encoding failed")
+	}
+}
+
+func writeAdminError(w http.ResponseWriter, r *http.Request, status int, err error) {
+	log.Ctx(r.Context()).Debug().Err(err).Msg("queue admin: request failed")
+	http.Error(w, err.Error(), status)
+}
@@ -0,0 +1,104 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package queue
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/adjust/rmq/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminHandler_invalidParameters(t *testing.T) {
+	h := AdminHandler()
+	for _, target := range []string{
+		"/debug/queues/orders/rejected?offset=-1",
+		"/debug/queues/orders/rejected?limit=0",
+		"/debug/queues/orders/rejected?limit=1001",
+		"/debug/queues/orders/rejected?limit=abc",
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		require.Equal(t, http.StatusBadRequest, rec.Code, target)
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/debug/queues/orders/rejected/return?count=0", nil))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestIntegrationAdminHandler(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	const name = "integrationTestAdmin"
+	q, err := OpenQueue(name)
+	require.NoError(t, err)
+	_, err = q.PurgeReady()
+	require.NoError(t, err)
+	_, err = q.PurgeRejected()
+	require.NoError(t, err)
+
+	// reject a delivery with a credit card number in the payload
+	require.NoError(t, q.Publish(rmq.PayloadWithHeader(`{"card":"4111111111111111"}`, http.Header{"Request-Id": {"abc"}})))
+	require.NoError(t, q.StartConsuming(1, 10))
+	done := make(chan struct{})
+	_, err = q.AddConsumerFunc("reject", func(d rmq.Delivery) {
+		require.NoError(t, d.Reject())
+		close(done)
+	})
+	require.NoError(t, err)
+	<-done
+	<-q.StopConsuming()
+
+	h := AdminHandler()
+	serve := func(method, target string, v any) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(v))
+	}
+
+	var queues []adminQueue
+	serve(http.MethodGet, "/debug/queues", &queues)
+	var found bool
+	for _, q := range queues {
+		if q.Name == name {
+			found = true
+			require.EqualValues(t, 1, q.Rejected)
+		}
+	}
+	require.True(t, found)
+
+	var rejected adminRejected
+	serve(http.MethodGet, "/debug/queues/"+name+"/rejected", &rejected)
+	require.EqualValues(t, 1, rejected.Total)
+	require.Len(t, rejected.Deliveries, 1)
+	require.Equal(t, "abc", rejected.Deliveries[0].Header.Get("Request-Id"))
+	require.NotContains(t, rejected.Deliveries[0].Payload, "4111111111111111")
+
+	var count adminCount
+	serve(http.MethodPost, "/debug/queues/"+name+"/rejected/return", &count)
+	require.EqualValues(t, 1, count.Count)
+
+	serve(http.MethodDelete, "/debug/queues/"+name+"/rejected", &count)
+	require.EqualValues(t, 0, count.Count)
+
+	// unknown queues are not opened
+	for _, method := range []string{http.MethodPost, http.MethodDelete} {
+		target := "/debug/queues/integrationTestAdminUnknown/rejected"
+		if method == http.MethodPost {
+			target += "/return"
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+		require.Equal(t, http.StatusNotFound, rec.Code, method)
+	}
+	serve(http.MethodGet, "/debug/queues", &queues)
+	for _, q := range queues {
+		require.NotEqual(t, "integrationTestAdminUnknown", q.Name)
+	}
+}
@@ -15,6 +15,10 @@ type config struct {
 	// signaled as unhealthy.
 	HealthCheckPendingStateInterval time.Duration `env:"RMQ_HEALTH_CHECK_PENDING_STATE_INTERVAL" envDefault:"1m"`
 	MetricsRefreshInterval          time.Duration `env:"RMQ_METRICS_REFRESH_INTERVAL" envDefault:"10s"`
+	// DelayedPollInterval is the interval in which delayed messages that are due are moved to their queue
+	DelayedPollInterval time.Duration `env:"RMQ_DELAYED_POLL_INTERVAL" envDefault:"1s"`
+	// DelayedBatchSize is the maximum number of delayed messages that are moved to their queue at once
+	DelayedBatchSize int64 `env:"RMQ_DELAYED_BATCH_SIZE" envDefault:"100"`
 }
 
 var cfg config
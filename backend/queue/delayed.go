@@ -0,0 +1,103 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/rs/xid"
+
+	pberrors "github.com/pace/bricks/maintenance/errors"
+	"github.com/pace/bricks/maintenance/log"
+)
+
+// Sorted set of the delayed messages of all queues, scored by the unix time
+// in milliseconds at which they are due.
+const delayedKey = "rmq::delayed"
+
+type delayedMessage struct {
+	// makes messages with the same payload unique within the sorted set
+	ID      string `json:"id"`
+	Queue   string `json:"queue"`
+	Payload string `json:"payload"`
+}
+
+// Stores the payloads until they are due at the given time and moved to the
+// named queue by promoteDelayed.
+func publishDelayed(ctx context.Context, queueName string, at time.Time, payloads ...string) error {
+	if err := initDefault(); err != nil {
+		return err
+	}
+	members := make([]goredis.Z, 0, len(payloads))
+	for _, payload := range payloads {
+		data, err := json.Marshal(delayedMessage{ID: xid.New().String(), Queue: queueName, Payload: payload})
+		if err != nil {
+			return err
+		}
+		members = append(members, goredis.Z{Score: float64(at.UnixMilli()), Member: string(data)})
+	}
+	if err := redisClient.ZAdd(ctx, delayedKey, members...).Err(); err != nil {
+		return fmt.Errorf("failed to publish delayed to queue %q: %w", queueName, err)
+	}
+	return nil
+}
+
+// Moves the delayed messages that are due to their queues until the context
+// is done. Must only run in a single instance, see initDefault.
+func promoteDelayed(ctx context.Context) {
+	for {
+		n, err := promoteDue(ctx)
+		if err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msg("rmq: could not promote delayed messages")
+			pberrors.Handle(ctx, err)
+		}
+
+		// continue immediately if there are more messages due
+		if err == nil && n == int(cfg.DelayedBatchSize) {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(cfg.DelayedPollInterval):
+		}
+	}
+}
+
+// Moves a batch of delayed messages that are due to their queues. Returns the
+// number of moved messages. A message is removed from the sorted set only
+// after it was published, so it is published again if the removal fails.
+func promoteDue(ctx context.Context) (int, error) {
+	members, err := redisClient.ZRangeByScore(ctx, delayedKey, &goredis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(time.Now().UnixMilli(), 10),
+		Count: cfg.DelayedBatchSize,
+	}).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to load delayed messages: %w", err)
+	}
+
+	for i, member := range members {
+		var msg delayedMessage
+		if err := json.Unmarshal([]byte(member), &msg); err != nil {
+			pberrors.Handle(ctx, fmt.Errorf("dropping invalid delayed message: %w", err))
+		} else {
+			queue, err := OpenQueue(msg.Queue)
+			if err != nil {
+				return i, err
+			}
+			if err := queue.Publish(msg.Payload); err != nil {
+				return i, fmt.Errorf("failed to promote delayed message to queue %q: %w", msg.Queue, err)
+			}
+		}
+		if err := redisClient.ZRem(ctx, delayedKey, member).Err(); err != nil {
+			return i, fmt.Errorf("failed to remove promoted delayed message: %w", err)
+		}
+	}
+	return len(members), nil
+}
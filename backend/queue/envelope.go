@@ -0,0 +1,92 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package queue
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/rs/xid"
+	"github.com/rs/zerolog"
+
+	"github.com/pace/bricks/locale"
+	"github.com/pace/bricks/maintenance/log"
+	"github.com/pace/bricks/maintenance/log/hlog"
+	"github.com/pace/bricks/maintenance/tracing/sampling"
+	"github.com/pace/bricks/pkg/tracking/utm"
+)
+
+// RequestIDHeader is the header of messages that contains the ID of the
+// request the message was published in.
+const RequestIDHeader = "Request-Id"
+
+// HeaderFromContext returns the message header that carries the request ID,
+// the trace, the locale and the UTM data of the context. Use it with
+// rmq.PayloadWithHeader to publish a message that is consumed with a Consumer.
+func HeaderFromContext(ctx context.Context) http.Header {
+	header := make(http.Header)
+	if reqID := log.RequestIDFromContext(ctx); reqID != "" {
+		header.Set(RequestIDHeader, reqID)
+	}
+	if span := sentry.SpanFromContext(ctx); span != nil {
+		header.Set(sentry.SentryTraceHeader, sampling.SentryTraceHeader(span))
+		if baggage := sampling.SentryBaggageHeader(span); baggage != "" {
+			header.Set(sentry.SentryBaggageHeader, baggage)
+		}
+	}
+	if loc, ok := locale.FromCtx(ctx); ok {
+		if loc.HasLanguage() {
+			header.Set(locale.HeaderAcceptLanguage, loc.Language())
+		}
+		if loc.HasTimezone() {
+			header.Set(locale.HeaderAcceptTimezone, loc.Timezone())
+		}
+	}
+	if data, ok := utm.FromContext(ctx); ok {
+		for k, v := range data.ToMap() {
+			if v != "" {
+				header.Set(k, v)
+			}
+		}
+	}
+	return header
+}
+
+// ContextWithHeader returns a context with a logger, a log sink, the request
+// ID, the locale and the UTM data taken from the message header. A request
+// ID is generated if the header has none. The trace is not part of the
+// context, it is continued by the transaction of the consumer.
+func ContextWithHeader(ctx context.Context, header http.Header) context.Context {
+	reqID, err := xid.FromString(header.Get(RequestIDHeader))
+	if err != nil {
+		reqID = xid.New()
+	}
+	ctx = hlog.WithValue(ctx, reqID)
+
+	logger := log.Logger().With().Str("req_id", reqID.String()).Logger()
+	ctx = log.ContextWithSink(logger.WithContext(ctx), log.NewSink())
+
+	if header.Get(locale.HeaderAcceptLanguage) != "" || header.Get(locale.HeaderAcceptTimezone) != "" {
+		ctx = locale.WithLocale(ctx, locale.NewLocale(header.Get(locale.HeaderAcceptLanguage), header.Get(locale.HeaderAcceptTimezone)))
+	}
+
+	utmMap := make(map[string]string)
+	for k := range (utm.UTMData{}).ToMap() {
+		if v := header.Get(k); v != "" {
+			utmMap[k] = v
+		}
+	}
+	if len(utmMap) > 0 {
+		ctx = utm.ContextWithUTMData(ctx, utm.FromMap(utmMap))
+	}
+
+	return ctx
+}
+
+// Adds the field to the logger of the context.
+func withLogField(ctx context.Context, key, value string) {
+	zerolog.Ctx(ctx).UpdateContext(func(c zerolog.Context) zerolog.Context {
+		return c.Str(key, value)
+	})
+}
@@ -17,15 +17,35 @@ type queueStatsGauges struct {
 	connectionGauge *prometheus.GaugeVec
 	consumerGauge   *prometheus.GaugeVec
 	unackedGauge    *prometheus.GaugeVec
+	delayedGauge    prometheus.Gauge
+}
+
+// Duration of processing a delivery by a Consumer, labelled by the result,
+// i.e. ack, reject, retry, dead_letter or panic
+var processingDurationHistogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: "rmq",
+	Name:      "processing_duration_seconds",
+	Help:      "Duration of processing a message of a queue",
+	Buckets:   []float64{.01, .05, .1, .5, 1, 5, 10, 30, 60},
+}, []string{"queue", "result"})
+
+func init() {
+	prometheus.MustRegister(processingDurationHistogram)
 }
 
 func gatherMetrics(connection rmq.Connection) {
 	gauges := registerConnection(connection)
 	ctx := log.ContextWithSink(log.WithContext(context.Background()), new(log.Sink))
 
-	routine.Run(ctx, func(_ context.Context) {
+	routine.Run(ctx, func(ctx context.Context) {
 		ticker := time.NewTicker(cfg.MetricsRefreshInterval)
-		for range ticker.C {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
 			queues, err := connection.GetOpenQueues()
 			if err != nil {
 				log.Ctx(ctx).Debug().Err(err).Msg("rmq metrics: could not get open queues")
@@ -46,6 +66,13 @@ func gatherMetrics(connection rmq.Connection) {
 				gauges.consumerGauge.With(labels).Set(float64(queueStats.ConsumerCount()))
 				gauges.unackedGauge.With(labels).Set(float64(queueStats.UnackedCount()))
 			}
+			delayed, err := redisClient.ZCard(ctx, delayedKey).Result()
+			if err != nil {
+				log.Ctx(ctx).Debug().Err(err).Msg("rmq metrics: could not count delayed messages")
+				pberrors.Handle(ctx, err)
+			} else {
+				gauges.delayedGauge.Set(float64(delayed))
+			}
 		}
 	})
 }
@@ -77,6 +104,11 @@ func registerConnection(connection rmq.Connection) queueStatsGauges {
 			Name:      "unacked",
 			Help:      "Number of unacked messages on a consumer",
 		}, []string{"queue"}),
+		delayedGauge: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "rmq",
+			Name:      "delayed",
+			Help:      "Number of delayed messages of all queues",
+		}),
 	}
 
 	prometheus.MustRegister(gauges.readyGauge)
@@ -84,6 +116,7 @@ func registerConnection(connection rmq.Connection) queueStatsGauges {
 	prometheus.MustRegister(gauges.connectionGauge)
 	prometheus.MustRegister(gauges.consumerGauge)
 	prometheus.MustRegister(gauges.unackedGauge)
+	prometheus.MustRegister(gauges.delayedGauge)
 
 	return gauges
 }
@@ -0,0 +1,33 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package outbox
+
+import (
+	"time"
+
+	"github.com/caarlos0/env/v11"
+
+	"github.com/pace/bricks/maintenance/log"
+)
+
+type config struct {
+	// Time to wait before polling for new messages if the outbox is empty
+	RelayPollInterval time.Duration `env:"OUTBOX_RELAY_POLL_INTERVAL" envDefault:"1s"`
+	// Maximum number of messages relayed in a single transaction
+	RelayBatchSize int `env:"OUTBOX_RELAY_BATCH_SIZE" envDefault:"100"`
+	// Amount of time relayed messages are kept to deduplicate new messages
+	Retention time.Duration `env:"OUTBOX_RETENTION" envDefault:"24h"`
+	// Age of the oldest message not yet relayed at which the outbox is unhealthy
+	HealthCheckMaxLag time.Duration `env:"OUTBOX_HEALTH_CHECK_MAX_LAG" envDefault:"1m"`
+	// Amount of time to cache the last health check result
+	HealthCheckResultTTL time.Duration `env:"OUTBOX_HEALTH_CHECK_RESULT_TTL" envDefault:"10s"`
+}
+
+var cfg config
+
+func init() {
+	err := env.Parse(&cfg)
+	if err != nil {
+		log.Fatalf("Failed to parse outbox environment: %v", err)
+	}
+}
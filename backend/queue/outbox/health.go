@@ -0,0 +1,39 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pace/bricks/maintenance/health/servicehealthcheck"
+)
+
+// HealthCheck checks that the relay of the outbox keeps up, i.e. that no
+// message waits longer than OUTBOX_HEALTH_CHECK_MAX_LAG to be relayed.
+type HealthCheck struct {
+	state  servicehealthcheck.ConnectionState
+	outbox *Outbox
+}
+
+// HealthCheck performs the health check.
+func (h *HealthCheck) HealthCheck(ctx context.Context) servicehealthcheck.HealthCheckResult {
+	if time.Since(h.state.LastChecked()) <= cfg.HealthCheckResultTTL {
+		// the last result of the Health Check is still not outdated
+		return h.state.GetState()
+	}
+
+	lag, err := h.outbox.lag(ctx)
+	if err != nil {
+		h.state.SetErrorState(err)
+		return h.state.GetState()
+	}
+	if lag > cfg.HealthCheckMaxLag {
+		h.state.SetErrorState(fmt.Errorf("outbox %q has a lag of %s, exceeding %s", h.outbox.table, lag.Round(time.Second), cfg.HealthCheckMaxLag))
+		return h.state.GetState()
+	}
+
+	h.state.SetHealthy()
+	return h.state.GetState()
+}
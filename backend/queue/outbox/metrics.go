@@ -0,0 +1,33 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package outbox
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	paceOutboxRelayedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "pace_outbox_relayed_total",
+			Help: "Collects stats about the number of messages relayed from the outbox to a queue",
+		},
+		[]string{"table", "queue"},
+	)
+	paceOutboxRelayFailed = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "pace_outbox_relay_failed",
+			Help: "Collects stats about the number of messages that could not be relayed to a queue",
+		},
+		[]string{"table", "queue"},
+	)
+	paceOutboxLagSeconds = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "pace_outbox_lag_seconds",
+			Help: "Age of the oldest message in the outbox that was not relayed yet",
+		},
+		[]string{"table"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(paceOutboxRelayedTotal, paceOutboxRelayFailed, paceOutboxLagSeconds)
+}
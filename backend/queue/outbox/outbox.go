@@ -0,0 +1,154 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+// Package outbox implements the transactional outbox pattern for queues. A
+// message is written to a postgres table in the same transaction as the
+// business data and is relayed to the rmq queue in the background afterwards.
+// That way a message is published if and only if the transaction commits.
+//
+// Messages are delivered at least once: if the relay stops after publishing a
+// message but before marking it as relayed, the message is published again.
+// Consumers should deduplicate using the MessageIDHeader.
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/adjust/rmq/v5"
+	"github.com/uptrace/bun"
+
+	"github.com/pace/bricks/backend/queue"
+)
+
+const (
+	// MessageIDHeader is the header of relayed messages that contains
+	// the ID of the message in the outbox. Messages that are delivered more
+	// than once have the same ID.
+	MessageIDHeader = "Outbox-Message-Id"
+	// DedupeKeyHeader is the header of relayed messages that contains
+	// the dedupe key the message was published with, if any.
+	DedupeKeyHeader = "Outbox-Dedupe-Key"
+)
+
+type message struct {
+	bun.BaseModel `bun:"alias:message"`
+
+	ID          int64       `bun:"id,pk,autoincrement"`
+	Queue       string      `bun:"queue,notnull"`
+	Payload     string      `bun:"payload,notnull"`
+	Header      http.Header `bun:"header,type:jsonb"`
+	DedupeKey   string      `bun:"dedupe_key,nullzero"`
+	CreatedAt   time.Time   `bun:"created_at,notnull,default:current_timestamp"`
+	PublishedAt time.Time   `bun:"published_at,nullzero"`
+}
+
+// Outbox stores messages in a postgres table until they are relayed to their
+// queue. It is safe for concurrent use.
+type Outbox struct {
+	db    *bun.DB
+	table string
+
+	// used to open the queues messages are relayed to
+	openQueue func(name string) (rmq.Queue, error)
+
+	queuesMx sync.Mutex
+	queues   map[string]rmq.Queue
+
+	registerHealthCheckOnce sync.Once
+}
+
+// New returns an outbox that stores messages in the given table of the
+// database. The table must be created using CreateTable or an equivalent
+// migration before the outbox is used.
+func New(db *bun.DB, table string) *Outbox {
+	return &Outbox{
+		db:        db,
+		table:     table,
+		openQueue: queue.OpenQueue,
+		queues:    make(map[string]rmq.Queue),
+	}
+}
+
+// CreateTable creates the table of the outbox if it doesn't exist yet.
+func (o *Outbox) CreateTable(ctx context.Context) error {
+	queries := []*bun.RawQuery{
+		o.db.NewRaw(`CREATE TABLE IF NOT EXISTS ? (
+			id bigserial PRIMARY KEY,
+			queue text NOT NULL,
+			payload text NOT NULL,
+			header jsonb,
+			dedupe_key text,
+			created_at timestamptz NOT NULL DEFAULT current_timestamp,
+			published_at timestamptz,
+			UNIQUE (queue, dedupe_key)
+		)`, bun.Ident(o.table)),
+		o.db.NewRaw(`CREATE INDEX IF NOT EXISTS ? ON ? (id) WHERE published_at IS NULL`,
+			bun.Ident(o.table+"_unpublished_idx"), bun.Ident(o.table)),
+		o.db.NewRaw(`CREATE INDEX IF NOT EXISTS ? ON ? (published_at) WHERE published_at IS NOT NULL`,
+			bun.Ident(o.table+"_published_idx"), bun.Ident(o.table)),
+	}
+	for _, q := range queries {
+		if _, err := q.Exec(ctx); err != nil {
+			return fmt.Errorf("outbox: failed to create table %q: %w", o.table, err)
+		}
+	}
+	return nil
+}
+
+// PublishOption configures a single message.
+type PublishOption func(*message)
+
+// WithDedupeKey sets a key that is unique for the queue. If a message with the
+// same key was already published to the queue within the retention time, the
+// message is dropped silently.
+func WithDedupeKey(key string) PublishOption {
+	return func(m *message) {
+		m.DedupeKey = key
+	}
+}
+
+// WithHeader sets the header that is passed with the message, see
+// rmq.PayloadWithHeader.
+func WithHeader(header http.Header) PublishOption {
+	return func(m *message) {
+		m.Header = header
+	}
+}
+
+// Publish stores the payload in the outbox as part of the transaction. The
+// payload is relayed to the named queue once the transaction committed and
+// the relay is running.
+func (o *Outbox) Publish(ctx context.Context, tx bun.Tx, queueName, payload string, opts ...PublishOption) error {
+	msg := &message{Queue: queueName, Payload: payload}
+	for _, opt := range opts {
+		opt(msg)
+	}
+
+	_, err := tx.NewInsert().Model(msg).
+		ModelTableExpr("?", bun.Ident(o.table)).
+		ExcludeColumn("id", "created_at", "published_at").
+		On("CONFLICT (queue, dedupe_key) DO NOTHING").
+		Returning("NULL").
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("outbox: failed to store message for queue %q: %w", queueName, err)
+	}
+	return nil
+}
+
+// Returns the header that is sent with the relayed message.
+func (m *message) relayHeader() http.Header {
+	header := m.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	header.Set(MessageIDHeader, strconv.FormatInt(m.ID, 10))
+	if m.DedupeKey != "" {
+		header.Set(DedupeKeyHeader, m.DedupeKey)
+	}
+	return header
+}
@@ -0,0 +1,73 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package outbox
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/adjust/rmq/v5"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+
+	"github.com/pace/bricks/backend/postgres"
+	"github.com/pace/bricks/maintenance/log"
+)
+
+func TestIntegrationOutbox(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	ctx := log.WithContext(context.Background())
+	db := postgres.NewDB(ctx)
+
+	o := New(db, "outbox_integration_test")
+	testQueue := rmq.NewTestQueue("outbox-test")
+	o.openQueue = func(string) (rmq.Queue, error) { return testQueue, nil }
+
+	require.NoError(t, o.CreateTable(ctx))
+	defer func() {
+		_, err := db.NewDropTable().TableExpr("?", bun.Ident(o.table)).IfExists().Exec(ctx)
+		require.NoError(t, err)
+	}()
+
+	// messages of a rolled back transaction are not relayed
+	err := db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		require.NoError(t, o.Publish(ctx, tx, "outbox-test", "rolled back"))
+		return context.Canceled
+	})
+	require.ErrorIs(t, err, context.Canceled)
+
+	err = db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		require.NoError(t, o.Publish(ctx, tx, "outbox-test", "first", WithDedupeKey("key")))
+		require.NoError(t, o.Publish(ctx, tx, "outbox-test", "duplicate", WithDedupeKey("key")))
+		return o.Publish(ctx, tx, "outbox-test", "second", WithHeader(http.Header{"Foo": {"bar"}}))
+	})
+	require.NoError(t, err)
+
+	n, err := o.relayBatch(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	require.Len(t, testQueue.LastDeliveries, 2)
+
+	header, payload, err := rmq.ExtractHeaderAndPayload(testQueue.LastDeliveries[0])
+	require.NoError(t, err)
+	require.Equal(t, "first", payload)
+	require.Equal(t, "key", header.Get(DedupeKeyHeader))
+	require.NotEmpty(t, header.Get(MessageIDHeader))
+
+	header, payload, err = rmq.ExtractHeaderAndPayload(testQueue.LastDeliveries[1])
+	require.NoError(t, err)
+	require.Equal(t, "second", payload)
+	require.Equal(t, "bar", header.Get("Foo"))
+
+	// relayed messages are not relayed again
+	n, err = o.relayBatch(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 0, n)
+
+	lag, err := o.lag(ctx)
+	require.NoError(t, err)
+	require.Zero(t, lag)
+}
@@ -0,0 +1,162 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/adjust/rmq/v5"
+	"github.com/uptrace/bun"
+
+	pberrors "github.com/pace/bricks/maintenance/errors"
+	"github.com/pace/bricks/maintenance/health/servicehealthcheck"
+	"github.com/pace/bricks/maintenance/log"
+	"github.com/pace/bricks/pkg/routine"
+)
+
+// StartRelay starts relaying the messages of the outbox to their queues in the
+// background. Across all callers sharing the same redis, only one relay per
+// table is running at a time, see routine.KeepRunningOneInstance. A health
+// check for the lag of the relay is registered as well. The relay stops once
+// the returned function is called.
+func (o *Outbox) StartRelay(ctx context.Context) context.CancelFunc {
+	o.registerHealthCheckOnce.Do(func() {
+		servicehealthcheck.RegisterHealthCheck("outbox:"+o.table, &HealthCheck{outbox: o})
+	})
+	return routine.RunNamed(ctx, "outbox:"+o.table, o.relay, routine.KeepRunningOneInstance())
+}
+
+func (o *Outbox) relay(ctx context.Context) {
+	var cleanedUpAt time.Time
+	for {
+		n, err := o.relayBatch(ctx)
+		if err != nil {
+			log.Ctx(ctx).Debug().Err(err).Str("table", o.table).Msg("outbox: could not relay messages")
+			pberrors.Handle(ctx, err)
+		}
+
+		if lag, err := o.lag(ctx); err == nil {
+			paceOutboxLagSeconds.WithLabelValues(o.table).Set(lag.Seconds())
+		}
+
+		if time.Since(cleanedUpAt) >= time.Minute {
+			if err := o.cleanUp(ctx); err != nil {
+				log.Ctx(ctx).Debug().Err(err).Str("table", o.table).Msg("outbox: could not clean up relayed messages")
+			}
+			cleanedUpAt = time.Now()
+		}
+
+		// continue immediately if there are more messages to relay
+		if err == nil && n == cfg.RelayBatchSize {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(cfg.RelayPollInterval):
+		}
+	}
+}
+
+// Relays a batch of messages in the order they were stored. Returns the number
+// of relayed messages. Messages that are relayed are marked as published, even
+// if relaying a later message of the batch fails.
+func (o *Outbox) relayBatch(ctx context.Context) (int, error) {
+	var relayed int
+	err := o.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		var msgs []message
+		err := tx.NewSelect().Model(&msgs).
+			ModelTableExpr("? AS message", bun.Ident(o.table)).
+			Where("published_at IS NULL").
+			OrderExpr("id ASC").
+			Limit(cfg.RelayBatchSize).
+			For("UPDATE SKIP LOCKED").
+			Scan(ctx)
+		if err != nil {
+			return fmt.Errorf("outbox: failed to select messages: %w", err)
+		}
+
+		ids := make([]int64, 0, len(msgs))
+		var relayErr error
+		for i := range msgs {
+			if relayErr = o.publish(&msgs[i]); relayErr != nil {
+				break
+			}
+			ids = append(ids, msgs[i].ID)
+		}
+		if len(ids) == 0 {
+			return relayErr
+		}
+
+		_, err = tx.NewUpdate().
+			TableExpr("?", bun.Ident(o.table)).
+			Set("published_at = current_timestamp").
+			Where("id IN (?)", bun.In(ids)).
+			Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("outbox: failed to mark messages as published: %w", err)
+		}
+		relayed = len(ids)
+		return relayErr
+	})
+	return relayed, err
+}
+
+// Publishes the message to its queue.
+func (o *Outbox) publish(msg *message) error {
+	q, err := o.getQueue(msg.Queue)
+	if err == nil {
+		err = q.Publish(rmq.PayloadWithHeader(msg.Payload, msg.relayHeader()))
+	}
+	if err != nil {
+		paceOutboxRelayFailed.WithLabelValues(o.table, msg.Queue).Inc()
+		return fmt.Errorf("outbox: failed to relay message %d to queue %q: %w", msg.ID, msg.Queue, err)
+	}
+	paceOutboxRelayedTotal.WithLabelValues(o.table, msg.Queue).Inc()
+	return nil
+}
+
+func (o *Outbox) getQueue(name string) (rmq.Queue, error) {
+	o.queuesMx.Lock()
+	defer o.queuesMx.Unlock()
+
+	if q, ok := o.queues[name]; ok {
+		return q, nil
+	}
+	q, err := o.openQueue(name)
+	if err != nil {
+		return nil, err
+	}
+	o.queues[name] = q
+	return q, nil
+}
+
+// Deletes relayed messages that are older than the retention time.
+func (o *Outbox) cleanUp(ctx context.Context) error {
+	_, err := o.db.NewDelete().
+		TableExpr("?", bun.Ident(o.table)).
+		Where("published_at < ?", time.Now().Add(-cfg.Retention)).
+		Exec(ctx)
+	return err
+}
+
+// Returns the age of the oldest message that was not relayed yet, or zero if
+// all messages were relayed.
+func (o *Outbox) lag(ctx context.Context) (time.Duration, error) {
+	var oldest sql.NullTime
+	err := o.db.NewSelect().
+		ColumnExpr("min(created_at)").
+		TableExpr("?", bun.Ident(o.table)).
+		Where("published_at IS NULL").
+		Scan(ctx, &oldest)
+	if err != nil {
+		return 0, fmt.Errorf("outbox: failed to determine lag: %w", err)
+	}
+	if !oldest.Valid {
+		return 0, nil
+	}
+	return time.Since(oldest.Time), nil
+}
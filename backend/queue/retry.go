@@ -0,0 +1,103 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package queue
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	exponential "github.com/jpillora/backoff"
+)
+
+const (
+	// AttemptHeader is the header of messages that contains the number of
+	// the attempt to process the message, starting with 1. It is missing on
+	// the first attempt.
+	AttemptHeader = "Queue-Attempt"
+	// LastErrorHeader is the header of retried and dead-lettered messages
+	// that contains the error of the last attempt.
+	LastErrorHeader = "Queue-Last-Error"
+)
+
+// RetryPolicy determines how a Consumer retries messages whose processing
+// failed with a retryable error, see Retryable.
+type RetryPolicy struct {
+	// MaxAttempts is the number of times a message is processed, including
+	// the first attempt. A value below 2 disables retries.
+	MaxAttempts int
+	// MinDelay and MaxDelay bound the exponential delay between two attempts.
+	// They default to 100ms and 10s.
+	MinDelay, MaxDelay time.Duration
+	// DeadLetterQueue is the name of the queue messages are moved to after the
+	// last attempt failed. If empty, the message is rejected instead.
+	DeadLetterQueue string
+	// DeadLetterHealthyLimit marks the health check as failing once the
+	// number of messages in the dead-letter queue exceeds it. Zero disables
+	// the check.
+	DeadLetterHealthyLimit int
+}
+
+// Returns the delay before the attempt following the given attempt.
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	b := exponential.Backoff{Min: p.MinDelay, Max: p.MaxDelay, Factor: 2, Jitter: true}
+	return b.ForAttempt(float64(attempt - 1))
+}
+
+// ConsumerOption configures a Consumer.
+type ConsumerOption func(*consumerOptions)
+
+type consumerOptions struct {
+	retryPolicy *RetryPolicy
+}
+
+// WithRetryPolicy retries messages whose processing failed with a retryable
+// error according to the policy.
+func WithRetryPolicy(policy RetryPolicy) ConsumerOption {
+	return func(o *consumerOptions) {
+		o.retryPolicy = &policy
+	}
+}
+
+type retryableError struct {
+	err error
+}
+
+func (e retryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e retryableError) Unwrap() error {
+	return e.err
+}
+
+// Retryable marks the error as retryable. If a Consumer with a RetryPolicy
+// receives it from its handler, the message is processed again later.
+func Retryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return retryableError{err: err}
+}
+
+// IsRetryable returns true if the error or any error it wraps was marked as
+// retryable.
+func IsRetryable(err error) bool {
+	var re retryableError
+	return errors.As(err, &re)
+}
+
+// Returns the attempt the message with the header is processed in.
+func attemptOf(header http.Header) int {
+	attempt, err := strconv.Atoi(header.Get(AttemptHeader))
+	if err != nil || attempt < 1 {
+		return 1
+	}
+	return attempt
+}
+
+// Dead-letter queues whose number of messages is checked by the HealthCheck,
+// mapped to their healthy limit.
+var deadLetterHealthLimits sync.Map
@@ -0,0 +1,111 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/adjust/rmq/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsumer_retries(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, MinDelay: time.Second, MaxDelay: time.Minute, DeadLetterQueue: "orders-dead"}
+	handler := func(context.Context, order) error { return Retryable(errors.New("timeout")) }
+
+	var delayed []string
+	var delayedAt time.Time
+	dlq := rmq.NewTestConnection()
+	c := NewConsumer("orders", handler, WithRetryPolicy(policy))
+	c.publishDelayed = func(_ context.Context, queueName string, at time.Time, payloads ...string) error {
+		require.Equal(t, "orders", queueName)
+		delayed, delayedAt = append(delayed, payloads...), at
+		return nil
+	}
+	c.openQueue = func(name string) (rmq.Queue, error) {
+		return dlq.OpenQueue(name)
+	}
+
+	// first attempt is retried
+	delivery := rmq.NewTestDeliveryString(`{"id":"42"}`)
+	c.Consume(delivery)
+	require.Equal(t, rmq.Acked, delivery.State)
+	require.Len(t, delayed, 1)
+	require.WithinDuration(t, time.Now().Add(time.Second), delayedAt, 2*time.Second)
+	header, payload, err := rmq.ExtractHeaderAndPayload(delayed[0])
+	require.NoError(t, err)
+	require.Equal(t, `{"id":"42"}`, payload)
+	require.Equal(t, "2", header.Get(AttemptHeader))
+	require.Contains(t, header.Get(LastErrorHeader), "timeout")
+
+	// second attempt is retried
+	delivery = rmq.NewTestDeliveryString(delayed[0])
+	c.Consume(delivery)
+	require.Equal(t, rmq.Acked, delivery.State)
+	require.Len(t, delayed, 2)
+	header, _, err = rmq.ExtractHeaderAndPayload(delayed[1])
+	require.NoError(t, err)
+	require.Equal(t, "3", header.Get(AttemptHeader))
+
+	// last attempt is dead-lettered
+	delivery = rmq.NewTestDeliveryString(delayed[1])
+	c.Consume(delivery)
+	require.Equal(t, rmq.Acked, delivery.State)
+	require.Len(t, delayed, 2)
+	deliveries := dlq.GetDeliveries("orders-dead")
+	require.Len(t, deliveries, 1)
+	header, payload, err = rmq.ExtractHeaderAndPayload(deliveries[0])
+	require.NoError(t, err)
+	require.Equal(t, `{"id":"42"}`, payload)
+	require.Contains(t, header.Get(LastErrorHeader), "timeout")
+}
+
+func TestConsumer_rejectsNonRetryableErrors(t *testing.T) {
+	c := NewConsumer("orders", func(context.Context, order) error {
+		return errors.New("invalid order")
+	}, WithRetryPolicy(RetryPolicy{MaxAttempts: 3, DeadLetterQueue: "orders-dead"}))
+	c.publishDelayed = func(context.Context, string, time.Time, ...string) error {
+		t.Fatal("message must not be retried")
+		return nil
+	}
+
+	delivery := rmq.NewTestDeliveryString(`{"id":"42"}`)
+	c.Consume(delivery)
+	require.Equal(t, rmq.Rejected, delivery.State)
+}
+
+func TestRetryPolicy_delay(t *testing.T) {
+	p := RetryPolicy{MinDelay: time.Second, MaxDelay: 5 * time.Second}
+	require.InDelta(t, time.Second, p.delay(1), float64(time.Second))
+	require.Equal(t, 5*time.Second, p.delay(10))
+}
+
+func TestIntegrationPublishDelayed(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	ctx := context.Background()
+	cfg.DelayedPollInterval = 100 * time.Millisecond
+
+	q, err := OpenQueue("integrationTestDelayed")
+	require.NoError(t, err)
+	_, err = q.PurgeReady()
+	require.NoError(t, err)
+
+	p, err := NewProducer[order]("integrationTestDelayed")
+	require.NoError(t, err)
+	require.NoError(t, p.PublishDelayed(ctx, time.Second, order{ID: "42"}))
+
+	readyCount := func() int64 {
+		stats, err := rmqConnection.CollectStats([]string{"integrationTestDelayed"})
+		require.NoError(t, err)
+		return stats.QueueStats["integrationTestDelayed"].ReadyCount
+	}
+	require.Zero(t, readyCount())
+	require.Eventually(t, func() bool {
+		return readyCount() == 1
+	}, 5*time.Second, 100*time.Millisecond)
+}
@@ -9,14 +9,17 @@ import (
 	"github.com/pace/bricks/backend/redis"
 	pberrors "github.com/pace/bricks/maintenance/errors"
 	"github.com/pace/bricks/maintenance/health/servicehealthcheck"
+	"github.com/pace/bricks/maintenance/lifecycle"
 	"github.com/pace/bricks/maintenance/log"
 	"github.com/pace/bricks/pkg/routine"
 
 	"github.com/adjust/rmq/v5"
+	goredis "github.com/redis/go-redis/v9"
 )
 
 var (
 	rmqConnection     rmq.Connection
+	redisClient       *goredis.Client
 	queueHealthLimits sync.Map
 
 	initMutex sync.Mutex
@@ -67,34 +70,47 @@ func initDefault() error {
 	ctx := log.ContextWithSink(log.WithContext(context.Background()), new(log.Sink))
 	routine.Run(ctx, func(ctx context.Context) {
 		for {
-			err := <-errChan
-			if err != nil {
-				pberrors.Handle(ctx, fmt.Errorf("rmq reported error in background task: %s", err))
+			select {
+			case <-ctx.Done():
+				return
+			case err := <-errChan:
+				if err != nil {
+					pberrors.Handle(ctx, fmt.Errorf("rmq reported error in background task: %s", err))
+				}
 			}
 		}
 	})
 
-	rmqConnection, err = rmq.OpenConnectionWithRedisClient("default", redis.Client(), errChan)
+	redisClient = redis.Client()
+	rmqConnection, err = rmq.OpenConnectionWithRedisClient("default", redisClient, errChan)
 	if err != nil {
 		rmqConnection = nil
 		return err
 	}
 	gatherMetrics(rmqConnection)
+	routine.RunNamed(ctx, "rmq:delayed", promoteDelayed, routine.KeepRunningOneInstance())
 	servicehealthcheck.RegisterHealthCheck("rmq", &HealthCheck{})
+	lifecycle.OnShutdown(lifecycle.PhaseConsumers, "rmq", stopAllConsuming)
 	return nil
 }
 
+// Stops consuming on all queues and waits until all consumers finished.
+func stopAllConsuming(ctx context.Context) error {
+	select {
+	case <-rmqConnection.StopAllConsuming():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // NewQueue creates a new rmq.Queue and initializes health checks for this queue
 // Whenever the number of items in the queue exceeds the healthyLimit
 // The queue will be reported as unhealthy
 // If the queue has already been opened, it will just be returned. Limits will not
 // be updated
 func NewQueue(name string, healthyLimit int) (rmq.Queue, error) {
-	err := initDefault()
-	if err != nil {
-		return nil, err
-	}
-	queue, err := rmqConnection.OpenQueue(name)
+	queue, err := OpenQueue(name)
 	if err != nil {
 		return nil, err
 	}
@@ -105,6 +121,16 @@ func NewQueue(name string, healthyLimit int) (rmq.Queue, error) {
 	return queue, nil
 }
 
+// OpenQueue opens the rmq.Queue without registering a health limit, e.g. to
+// only publish to a queue that is consumed elsewhere.
+func OpenQueue(name string) (rmq.Queue, error) {
+	err := initDefault()
+	if err != nil {
+		return nil, err
+	}
+	return rmqConnection.OpenQueue(name)
+}
+
 type HealthCheck struct {
 	state servicehealthcheck.ConnectionState
 	// IgnoreInterval is a switch used for testing, just to allow multiple
@@ -114,6 +140,8 @@ type HealthCheck struct {
 
 // HealthCheck checks if the queues are healthy, i.e. whether the number of
 // items accumulated is below the healthyLimit defined when opening the queue
+// and whether the number of items in the dead-letter queues is below the
+// DeadLetterHealthyLimit of their RetryPolicy
 func (h *HealthCheck) HealthCheck(ctx context.Context) servicehealthcheck.HealthCheckResult {
 	if !h.IgnoreInterval && time.Since(h.state.LastChecked()) <= cfg.HealthCheckResultTTL {
 		return h.state.GetState()
@@ -131,6 +159,7 @@ func (h *HealthCheck) HealthCheck(ctx context.Context) servicehealthcheck.Health
 		h.state.SetErrorState(fmt.Errorf("error while collecting stats: %s", err))
 		return h.state.GetState()
 	}
+	healthy := true
 	queueHealthLimits.Range(func(k, v interface{}) bool {
 		name := k.(string)
 		hl := v.(*queueHealth)
@@ -147,11 +176,24 @@ func (h *HealthCheck) HealthCheck(ctx context.Context) servicehealthcheck.Health
 			}
 
 			h.state.SetErrorState(fmt.Errorf("Queue '%s' exceeded safe health limit of '%d'", name, hl.limit))
+			healthy = false
 			return false
 		}
 		h.state.SetHealthy()
 		hl.markHealthy()
 		return true
 	})
+	if !healthy {
+		return h.state.GetState()
+	}
+	deadLetterHealthLimits.Range(func(k, v interface{}) bool {
+		name, limit := k.(string), v.(int)
+		if count := stats.QueueStats[name].ReadyCount; count > int64(limit) {
+			h.state.SetErrorState(fmt.Errorf("Dead-letter queue '%s' exceeded safe health limit of '%d'", name, limit))
+			return false
+		}
+		h.state.SetHealthy()
+		return true
+	})
 	return h.state.GetState()
 }
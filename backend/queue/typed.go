@@ -0,0 +1,241 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/adjust/rmq/v5"
+	"github.com/getsentry/sentry-go"
+
+	pberrors "github.com/pace/bricks/maintenance/errors"
+	"github.com/pace/bricks/maintenance/log"
+)
+
+// Producer publishes values of type T to a queue. The values are encoded as
+// JSON, the request ID, the trace, the locale and the UTM data of the context
+// are passed in the header of the message.
+type Producer[T any] struct {
+	name  string
+	queue rmq.Queue
+}
+
+// NewProducer returns a producer for the named queue. Like OpenQueue it does
+// not register a health limit for the queue.
+func NewProducer[T any](name string) (*Producer[T], error) {
+	queue, err := OpenQueue(name)
+	if err != nil {
+		return nil, err
+	}
+	return &Producer[T]{name: name, queue: queue}, nil
+}
+
+// Publish encodes the values and publishes them to the queue.
+func (p *Producer[T]) Publish(ctx context.Context, values ...T) error {
+	payloads, err := p.encode(ctx, values)
+	if err != nil {
+		return err
+	}
+	if err := p.queue.Publish(payloads...); err != nil {
+		return fmt.Errorf("failed to publish to queue %q: %w", p.name, err)
+	}
+	return nil
+}
+
+// PublishDelayed encodes the values and publishes them to the queue once the
+// delay passed. The delay is only approximate, see RMQ_DELAYED_POLL_INTERVAL.
+func (p *Producer[T]) PublishDelayed(ctx context.Context, delay time.Duration, values ...T) error {
+	payloads, err := p.encode(ctx, values)
+	if err != nil {
+		return err
+	}
+	return publishDelayed(ctx, p.name, time.Now().Add(delay), payloads...)
+}
+
+// Returns the encoded values with the header of the context.
+func (p *Producer[T]) encode(ctx context.Context, values []T) ([]string, error) {
+	header := HeaderFromContext(ctx)
+	payloads := make([]string, 0, len(values))
+	for _, v := range values {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode message for queue %q: %w", p.name, err)
+		}
+		payloads = append(payloads, rmq.PayloadWithHeader(string(data), header))
+	}
+	return payloads, nil
+}
+
+// Consumer is a rmq.Consumer that decodes the messages of a queue into values
+// of type T and passes them to a handler. Add it to the queue using
+// AddConsumer after the queue started consuming:
+//
+//	q, err := queue.NewQueue("orders", 1000)
+//	...
+//	err = q.StartConsuming(10, time.Second)
+//	...
+//	_, err = q.AddConsumer("orders", queue.NewConsumer("orders", handleOrder))
+//
+// The handler is called with a context that carries the request ID, the trace,
+// the locale and the UTM data of the publisher. The delivery is acked if the
+// handler returns nil and rejected if it returns an error or panics. Errors
+// and panics are reported to sentry. See WithRetryPolicy to retry messages
+// instead.
+type Consumer[T any] struct {
+	name    string
+	handler func(ctx context.Context, value T) error
+	consumerOptions
+
+	// used to publish retried and dead-lettered messages
+	openQueue      func(name string) (rmq.Queue, error)
+	publishDelayed func(ctx context.Context, queueName string, at time.Time, payloads ...string) error
+}
+
+// NewConsumer returns a consumer for the named queue that passes the decoded
+// values to the handler.
+func NewConsumer[T any](name string, handler func(ctx context.Context, value T) error, opts ...ConsumerOption) *Consumer[T] {
+	c := &Consumer[T]{
+		name:           name,
+		handler:        handler,
+		openQueue:      OpenQueue,
+		publishDelayed: publishDelayed,
+	}
+	for _, opt := range opts {
+		opt(&c.consumerOptions)
+	}
+	if p := c.retryPolicy; p != nil && p.DeadLetterQueue != "" && p.DeadLetterHealthyLimit > 0 {
+		deadLetterHealthLimits.Store(p.DeadLetterQueue, p.DeadLetterHealthyLimit)
+	}
+	return c
+}
+
+// Consume implements rmq.Consumer.
+func (c *Consumer[T]) Consume(delivery rmq.Delivery) {
+	header, payload, err := headerAndPayload(delivery)
+
+	ctx := ContextWithHeader(context.Background(), header)
+	withLogField(ctx, "queue", c.name)
+
+	hub := sentry.CurrentHub().Clone()
+	ctx = sentry.SetHubOnContext(ctx, hub)
+	span := sentry.StartTransaction(ctx, "Consume "+c.name,
+		sentry.WithOpName("queue.process"),
+		sentry.ContinueTrace(hub, header.Get(sentry.SentryTraceHeader), header.Get(sentry.SentryBaggageHeader)),
+	)
+	defer span.Finish()
+	ctx = span.Context()
+
+	start := time.Now()
+	result := "panic"
+	defer func() {
+		processingDurationHistogram.WithLabelValues(c.name, result).Observe(time.Since(start).Seconds())
+	}()
+	defer func() {
+		if result == "panic" {
+			span.Status = sentry.SpanStatusInternalError
+			reject(ctx, delivery)
+		}
+	}()
+	defer pberrors.HandleWithCtx(ctx, "queue "+c.name)
+
+	if err != nil {
+		result = "reject"
+		span.Status = sentry.SpanStatusInvalidArgument
+		pberrors.Handle(ctx, fmt.Errorf("failed to read message of queue %q: %w", c.name, err))
+		reject(ctx, delivery)
+		return
+	}
+
+	var value T
+	if err := json.Unmarshal([]byte(payload), &value); err != nil {
+		result = "reject"
+		span.Status = sentry.SpanStatusInvalidArgument
+		pberrors.Handle(ctx, fmt.Errorf("failed to decode message of queue %q: %w", c.name, err))
+		reject(ctx, delivery)
+		return
+	}
+
+	if err := c.handler(ctx, value); err != nil {
+		span.Status = sentry.SpanStatusInternalError
+		result = c.fail(ctx, delivery, header, payload, err)
+		return
+	}
+
+	result = "ack"
+	span.Status = sentry.SpanStatusOK
+	ack(ctx, delivery)
+}
+
+// Handles the failed processing of the delivery by retrying, dead-lettering or
+// rejecting it. Returns the result for the processing duration histogram.
+func (c *Consumer[T]) fail(ctx context.Context, delivery rmq.Delivery, header http.Header, payload string, err error) string {
+	err = fmt.Errorf("failed to process message of queue %q: %w", c.name, err)
+	p := c.retryPolicy
+	if p == nil || !IsRetryable(err) {
+		pberrors.Handle(ctx, err)
+		reject(ctx, delivery)
+		return "reject"
+	}
+
+	attempt := attemptOf(header)
+	header = header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	header.Set(LastErrorHeader, err.Error())
+
+	if attempt < p.MaxAttempts {
+		delay := p.delay(attempt)
+		log.Ctx(ctx).Warn().Err(err).Int("attempt", attempt).Dur("delay", delay).Msg("retrying message")
+		header.Set(AttemptHeader, strconv.Itoa(attempt+1))
+		if err := c.publishDelayed(ctx, c.name, time.Now().Add(delay), rmq.PayloadWithHeader(payload, header)); err != nil {
+			pberrors.Handle(ctx, err)
+			reject(ctx, delivery)
+			return "reject"
+		}
+		ack(ctx, delivery)
+		return "retry"
+	}
+
+	pberrors.Handle(ctx, err)
+	if p.DeadLetterQueue == "" {
+		reject(ctx, delivery)
+		return "reject"
+	}
+	dlq, err := c.openQueue(p.DeadLetterQueue)
+	if err == nil {
+		err = dlq.Publish(rmq.PayloadWithHeader(payload, header))
+	}
+	if err != nil {
+		pberrors.Handle(ctx, fmt.Errorf("failed to move message to dead-letter queue %q: %w", p.DeadLetterQueue, err))
+		reject(ctx, delivery)
+		return "reject"
+	}
+	ack(ctx, delivery)
+	return "dead_letter"
+}
+
+// Returns the header and the original payload of the delivery.
+func headerAndPayload(delivery rmq.Delivery) (http.Header, string, error) {
+	if d, ok := delivery.(rmq.WithHeader); ok {
+		return d.Header(), delivery.Payload(), nil
+	}
+	return rmq.ExtractHeaderAndPayload(delivery.Payload())
+}
+
+func ack(ctx context.Context, delivery rmq.Delivery) {
+	if err := delivery.Ack(); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("failed to ack message")
+	}
+}
+
+func reject(ctx context.Context, delivery rmq.Delivery) {
+	if err := delivery.Reject(); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("failed to reject message")
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/adjust/rmq/v5"
+	"github.com/rs/xid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pace/bricks/locale"
+	"github.com/pace/bricks/maintenance/log"
+	"github.com/pace/bricks/maintenance/log/hlog"
+	"github.com/pace/bricks/pkg/tracking/utm"
+)
+
+type order struct {
+	ID string `json:"id"`
+}
+
+func TestConsumer_propagatesContext(t *testing.T) {
+	reqID := xid.New()
+	ctx := hlog.WithValue(context.Background(), reqID)
+	ctx = locale.WithLocale(ctx, locale.NewLocale("de-DE", "Europe/Berlin"))
+	ctx = utm.ContextWithUTMData(ctx, utm.UTMData{Source: "newsletter", Client: "app"})
+
+	delivery := rmq.NewTestDeliveryString(rmq.PayloadWithHeader(`{"id":"42"}`, HeaderFromContext(ctx)))
+
+	var got order
+	NewConsumer("orders", func(ctx context.Context, o order) error {
+		got = o
+		require.Equal(t, reqID.String(), log.RequestIDFromContext(ctx))
+		loc, ok := locale.FromCtx(ctx)
+		require.True(t, ok)
+		require.Equal(t, "de-DE", loc.Language())
+		require.Equal(t, "Europe/Berlin", loc.Timezone())
+		data, ok := utm.FromContext(ctx)
+		require.True(t, ok)
+		require.Equal(t, utm.UTMData{Source: "newsletter", Client: "app"}, data)
+		return nil
+	}).Consume(delivery)
+
+	require.Equal(t, order{ID: "42"}, got)
+	require.Equal(t, rmq.Acked, delivery.State)
+}
+
+func TestConsumer_rejects(t *testing.T) {
+	cases := map[string]struct {
+		payload string
+		handler func(context.Context, order) error
+	}{
+		"handler error": {
+			payload: `{"id":"42"}`,
+			handler: func(context.Context, order) error { return errors.New("failed") },
+		},
+		"handler panic": {
+			payload: `{"id":"42"}`,
+			handler: func(context.Context, order) error { panic("failed") },
+		},
+		"invalid payload": {
+			payload: `{"id":42}`,
+			handler: func(context.Context, order) error {
+				t.Fatal("handler must not be called")
+				return nil
+			},
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			delivery := rmq.NewTestDeliveryString(c.payload)
+			NewConsumer("orders", c.handler).Consume(delivery)
+			require.Equal(t, rmq.Rejected, delivery.State)
+		})
+	}
+}
+
+func TestContextWithHeader_generatesRequestID(t *testing.T) {
+	ctx := ContextWithHeader(context.Background(), nil)
+	require.NotEmpty(t, log.RequestIDFromContext(ctx))
+	_, ok := locale.FromCtx(ctx)
+	require.False(t, ok)
+	_, ok = utm.FromContext(ctx)
+	require.False(t, ok)
+}
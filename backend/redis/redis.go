@@ -11,9 +11,13 @@ import (
 	"github.com/getsentry/sentry-go"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/pace/bricks/maintenance/health/servicehealthcheck"
 	"github.com/pace/bricks/maintenance/log"
+	"github.com/pace/bricks/maintenance/tracing"
 )
 
 type config struct {
@@ -166,6 +170,10 @@ func (lt *logtracer) DialHook(next redis.DialHook) redis.DialHook {
 
 func (lt *logtracer) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
 	return func(ctx context.Context, cmd redis.Cmder) error {
+		if tracing.OTelEnabled() {
+			return lt.processOTel(ctx, cmd, next)
+		}
+
 		startedAt := time.Now()
 
 		span := sentry.StartSpan(ctx, "db.redis", sentry.WithDescription(cmd.Name()))
@@ -210,6 +218,43 @@ func (lt *logtracer) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
 	}
 }
 
+// processOTel is like ProcessHook, but creates an OpenTelemetry span.
+func (lt *logtracer) processOTel(ctx context.Context, cmd redis.Cmder, next redis.ProcessHook) error {
+	startedAt := time.Now()
+
+	ctx, span := tracing.Tracer().Start(ctx, cmd.Name(),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "redis"),
+			attribute.String("db.operation.name", cmd.Name()),
+		),
+	)
+	defer span.End()
+
+	paceRedisCmdTotal.With(prometheus.Labels{
+		"method": cmd.Name(),
+	}).Inc()
+
+	_ = next(ctx, cmd)
+
+	// add error
+	if cmdErr := cmd.Err(); cmdErr != nil {
+		span.RecordError(cmdErr)
+		span.SetStatus(codes.Error, cmdErr.Error())
+		log.Ctx(ctx).Debug().Str("cmd", cmd.Name()).Str("sentry:category", "redis").Err(cmdErr).Msg("failed to execute Redis command")
+		paceRedisCmdFailed.With(prometheus.Labels{
+			"method": cmd.Name(),
+		}).Inc()
+	}
+
+	dur := float64(time.Since(startedAt)) / float64(time.Millisecond)
+	paceRedisCmdDurationSeconds.With(prometheus.Labels{
+		"method": cmd.Name(),
+	}).Observe(dur)
+
+	return nil
+}
+
 func (l *logtracer) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
 	return next
 }
@@ -7,17 +7,17 @@ import (
 	"time"
 
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
 
 	"github.com/pace/bricks/http/middleware"
 	"github.com/pace/bricks/http/security"
 	"github.com/pace/bricks/locale"
 	"github.com/pace/bricks/maintenance/log"
+	"github.com/pace/bricks/maintenance/tracing"
 
 	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-middleware/providers/prometheus"
 	grpc_retry "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/retry"
-	grpc_sentry "github.com/johnbellone/grpc-middleware-sentry"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 )
 
 // Deprecated: Use NewClient instead.
@@ -30,21 +30,51 @@ func Dial(addr string) (*grpc.ClientConn, error) {
 	return NewClient(addr)
 }
 
-func NewClient(addr string) (*grpc.ClientConn, error) {
+// NewClient creates a client for the address. By default the calls are
+// retried using DefaultRetryPolicy, the options allow to configure retries,
+// deadlines, circuit breaking, load balancing and keepalive. The transport
+// credentials are configured using environment variables, see
+// ClientCredentials.
+func NewClient(addr string, opts ...ClientOption) (*grpc.ClientConn, error) {
 	var conn *grpc.ClientConn
 
+	cfg := newClientConfig(opts)
 	clientMetrics := grpc_prometheus.NewClientMetrics()
 
-	opts := []grpc_retry.CallOption{
-		grpc_retry.WithBackoff(grpc_retry.BackoffLinear(100 * time.Millisecond)),
-		grpc_retry.WithMax(10),
+	creds, err := ClientCredentials()
+	if err != nil {
+		return nil, err
 	}
 
-	conn, err := grpc.NewClient(addr,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	unaryInterceptors := []grpc.UnaryClientInterceptor{
+		clientMetrics.UnaryClientInterceptor(),
+		sentryUnaryClientInterceptor(),
+		cfg.timeoutUnaryInterceptor(),
+	}
+	if breaker := cfg.breakerUnaryInterceptor(); breaker != nil {
+		unaryInterceptors = append(unaryInterceptors, breaker)
+	}
+	unaryInterceptors = append(unaryInterceptors,
+		cfg.retryUnaryInterceptor(),
+		grpc_retry.UnaryClientInterceptor(),
+		func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			start := time.Now()
+			err := invoker(prepareClientContext(ctx), method, req, reply, cc, opts...)
+			log.Ctx(ctx).Debug().Str("method", method).
+				Dur("duration", time.Since(start)).
+				Str("type", "unary").
+				Err(err).
+				Msg("GRPC requested")
+			return err
+		},
+	)
+
+	dialOpts := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
 		grpc.WithChainStreamInterceptor(
-			grpc_sentry.StreamClientInterceptor(),
-			grpc_retry.StreamClientInterceptor(opts...),
+			sentryStreamClientInterceptor(),
+			cfg.retryStreamInterceptor(),
+			grpc_retry.StreamClientInterceptor(),
 			func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
 				start := time.Now()
 				cs, err := streamer(prepareClientContext(ctx), desc, cc, method, opts...)
@@ -56,22 +86,15 @@ func NewClient(addr string) (*grpc.ClientConn, error) {
 				return cs, err
 			},
 		),
-		grpc.WithChainUnaryInterceptor(
-			clientMetrics.UnaryClientInterceptor(),
-			grpc_sentry.UnaryClientInterceptor(),
-			grpc_retry.UnaryClientInterceptor(opts...),
-			func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
-				start := time.Now()
-				err := invoker(prepareClientContext(ctx), method, req, reply, cc, opts...)
-				log.Ctx(ctx).Debug().Str("method", method).
-					Dur("duration", time.Since(start)).
-					Str("type", "unary").
-					Err(err).
-					Msg("GRPC requested")
-				return err
-			},
-		),
-	)
+		grpc.WithChainUnaryInterceptor(unaryInterceptors...),
+	}
+
+	// propagate the trace context and baggage, see TRACING_MODE
+	if tracing.OTelEnabled() {
+		dialOpts = append(dialOpts, grpc.WithStatsHandler(otelgrpc.NewClientHandler()))
+	}
+
+	conn, err = grpc.NewClient(cfg.target(addr), append(dialOpts, cfg.extraDialOptions()...)...)
 	return conn, err
 }
 
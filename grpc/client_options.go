@@ -0,0 +1,300 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	grpc_retry "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/retry"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sony/gobreaker/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/status"
+)
+
+// ErrCircuitBroken is returned, with codes.Unavailable, if the circuit
+// breaker of the client is open.
+var ErrCircuitBroken = errors.New("circuit broken")
+
+// Codes that are retried for methods that are not idempotent, they indicate
+// that the call was not processed.
+var safeRetryCodes = []codes.Code{codes.Unavailable, codes.ResourceExhausted}
+
+// RetryPolicy configures the retries of calls. Server streams are retried as
+// long as no message was received, client streams are not retried.
+type RetryPolicy struct {
+	// Maximum number of retries, 0 disables retries
+	Max uint
+	// Backoff between the retries, defaults to 100ms
+	Backoff grpc_retry.BackoffFunc
+	// Codes that are retried, defaults to codes.Unavailable and
+	// codes.ResourceExhausted
+	Codes []codes.Code
+	// Idempotent methods are retried on all Codes, other methods only on
+	// codes.Unavailable and codes.ResourceExhausted
+	Idempotent bool
+	// Timeout of each attempt, 0 disables the timeout
+	PerAttemptTimeout time.Duration
+}
+
+// DefaultRetryPolicy is the retry policy of methods without specific policy.
+var DefaultRetryPolicy = RetryPolicy{
+	Max:     10,
+	Backoff: grpc_retry.BackoffLinear(100 * time.Millisecond),
+}
+
+func (p RetryPolicy) callOptions() []grpc.CallOption {
+	backoff := p.Backoff
+	if backoff == nil {
+		backoff = grpc_retry.BackoffLinear(100 * time.Millisecond)
+	}
+
+	retryCodes := p.Codes
+	if retryCodes == nil {
+		retryCodes = safeRetryCodes
+	}
+	if !p.Idempotent {
+		retryCodes = slices.DeleteFunc(slices.Clone(retryCodes), func(c codes.Code) bool {
+			return !slices.Contains(safeRetryCodes, c)
+		})
+	}
+
+	return []grpc.CallOption{
+		grpc_retry.WithMax(p.Max),
+		grpc_retry.WithBackoff(backoff),
+		grpc_retry.WithCodes(retryCodes...),
+		grpc_retry.WithPerRetryTimeout(p.PerAttemptTimeout),
+	}
+}
+
+// methodRule applies a value to the methods matching the pattern
+type methodRule[T any] struct {
+	pattern string
+	value   T
+}
+
+// match returns the value of the first rule matching the full method, or def
+func match[T any](rules []methodRule[T], method string, def T) T {
+	for _, r := range rules {
+		if ok, _ := path.Match(r.pattern, method); ok {
+			return r.value
+		}
+	}
+	return def
+}
+
+type clientConfig struct {
+	retry          RetryPolicy
+	methodRetries  []methodRule[RetryPolicy]
+	timeout        time.Duration
+	methodTimeouts []methodRule[time.Duration]
+	breaker        *gobreaker.Settings
+	roundRobin     bool
+	dialOptions    []grpc.DialOption
+}
+
+// ClientOption configures a client created by NewClient.
+type ClientOption func(*clientConfig)
+
+// WithRetryPolicy sets the retry policy of the methods without specific
+// policy, it defaults to DefaultRetryPolicy.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(cfg *clientConfig) {
+		cfg.retry = policy
+	}
+}
+
+// WithMethodRetryPolicy sets the retry policy of the methods matching the
+// pattern. The full methods, e.g. "/package.Service/Method", are matched
+// using path.Match in the order the policies were added.
+func WithMethodRetryPolicy(pattern string, policy RetryPolicy) ClientOption {
+	return func(cfg *clientConfig) {
+		cfg.methodRetries = append(cfg.methodRetries, methodRule[RetryPolicy]{pattern: pattern, value: policy})
+	}
+}
+
+// WithTimeout sets the default deadline of unary calls without deadline,
+// including all retries.
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(cfg *clientConfig) {
+		cfg.timeout = timeout
+	}
+}
+
+// WithMethodTimeout sets the default deadline of the unary methods matching
+// the pattern, see WithTimeout and WithMethodRetryPolicy.
+func WithMethodTimeout(pattern string, timeout time.Duration) ClientOption {
+	return func(cfg *clientConfig) {
+		cfg.methodTimeouts = append(cfg.methodTimeouts, methodRule[time.Duration]{pattern: pattern, value: timeout})
+	}
+}
+
+// WithCircuitBreaker guards the unary calls with a gobreaker circuit
+// breaker, including all retries. The name of the settings is mandatory. By
+// default only the codes codes.Unavailable, codes.DeadlineExceeded,
+// codes.Internal, codes.Unknown and codes.DataLoss count as failures.
+func WithCircuitBreaker(settings gobreaker.Settings) ClientOption {
+	return func(cfg *clientConfig) {
+		cfg.breaker = &settings
+	}
+}
+
+// WithRoundRobin balances the calls over all addresses of the target, e.g.
+// the pods of a headless kubernetes service. Targets without scheme are
+// resolved using DNS.
+func WithRoundRobin() ClientOption {
+	return func(cfg *clientConfig) {
+		cfg.roundRobin = true
+	}
+}
+
+// WithKeepalive sets the keepalive parameters of the connections.
+func WithKeepalive(params keepalive.ClientParameters) ClientOption {
+	return func(cfg *clientConfig) {
+		cfg.dialOptions = append(cfg.dialOptions, grpc.WithKeepaliveParams(params))
+	}
+}
+
+// WithPerRPCCredentials authenticates the calls using the credentials, e.g.
+// TokenCredentials.
+func WithPerRPCCredentials(creds credentials.PerRPCCredentials) ClientOption {
+	return WithDialOptions(grpc.WithPerRPCCredentials(creds))
+}
+
+// WithDialOptions passes further options to grpc.NewClient.
+func WithDialOptions(opts ...grpc.DialOption) ClientOption {
+	return func(cfg *clientConfig) {
+		cfg.dialOptions = append(cfg.dialOptions, opts...)
+	}
+}
+
+func newClientConfig(opts []ClientOption) *clientConfig {
+	cfg := &clientConfig{retry: DefaultRetryPolicy}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// target returns the target of grpc.NewClient for the address
+func (cfg *clientConfig) target(addr string) string {
+	if cfg.roundRobin && !strings.Contains(addr, ":///") {
+		return "dns:///" + addr
+	}
+	return addr
+}
+
+// extraDialOptions returns the options for the load balancing and the
+// options passed by WithDialOptions
+func (cfg *clientConfig) extraDialOptions() []grpc.DialOption {
+	var opts []grpc.DialOption
+	if cfg.roundRobin {
+		opts = append(opts, grpc.WithDefaultServiceConfig(`{"loadBalancingConfig": [{"round_robin": {}}]}`))
+	}
+	return append(opts, cfg.dialOptions...)
+}
+
+// timeoutUnaryInterceptor sets the default deadline of calls without deadline
+func (cfg *clientConfig) timeoutUnaryInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if _, ok := ctx.Deadline(); !ok {
+			if timeout := match(cfg.methodTimeouts, method, cfg.timeout); timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, timeout)
+				defer cancel()
+			}
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// retryUnaryInterceptor passes the retry policy of the method to the retry
+// interceptor, options of the call take precedence
+func (cfg *clientConfig) retryUnaryInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		policy := match(cfg.methodRetries, method, cfg.retry)
+		return invoker(ctx, method, req, reply, cc, append(policy.callOptions(), opts...)...)
+	}
+}
+
+// retryStreamInterceptor passes the retry policy of the method to the retry
+// interceptor, options of the call take precedence
+func (cfg *clientConfig) retryStreamInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		policy := match(cfg.methodRetries, method, cfg.retry)
+		return streamer(ctx, desc, cc, method, append(policy.callOptions(), opts...)...)
+	}
+}
+
+// breakerUnaryInterceptor returns an interceptor guarding the calls with a
+// circuit breaker, or nil if no circuit breaker is configured
+func (cfg *clientConfig) breakerUnaryInterceptor() grpc.UnaryClientInterceptor {
+	if cfg.breaker == nil {
+		return nil
+	}
+	breaker := newCircuitBreaker(*cfg.breaker)
+
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		_, err := breaker.Execute(func() (struct{}, error) {
+			return struct{}{}, invoker(ctx, method, req, reply, cc, opts...)
+		})
+		if errors.Is(err, gobreaker.ErrOpenState) || errors.Is(err, gobreaker.ErrTooManyRequests) {
+			return status.Errorf(codes.Unavailable, "%v: considering %q unreachable", ErrCircuitBroken, cc.Target())
+		}
+		return err
+	}
+}
+
+func newCircuitBreaker(settings gobreaker.Settings) *gobreaker.CircuitBreaker[struct{}] {
+	if settings.Name == "" {
+		panic("name is mandatory for circuit breaker")
+	}
+
+	stateSwitchCounterVec := prometheus.NewCounterVec(prometheus.CounterOpts{
+		ConstLabels: prometheus.Labels{"name": settings.Name},
+		Name:        "pace_grpc_circuit_breaker_state_switch_total",
+		Help:        "Collects the state switches of the grpc client circuit breakers",
+	}, []string{"from", "to"})
+
+	var ok bool
+	var are prometheus.AlreadyRegisteredError
+	if err := prometheus.Register(stateSwitchCounterVec); errors.As(err, &are) {
+		stateSwitchCounterVec, ok = are.ExistingCollector.(*prometheus.CounterVec)
+		if !ok {
+			panic(fmt.Sprintf(`existing "pace_grpc_circuit_breaker_state_switch_total" collector no CounterVec, but %T`, are.ExistingCollector))
+		}
+	} else if err != nil {
+		panic(err)
+	}
+
+	handler := settings.OnStateChange
+	settings.OnStateChange = func(s string, from, to gobreaker.State) {
+		if handler != nil {
+			handler(s, from, to)
+		}
+
+		labels := prometheus.Labels{"from": from.String(), "to": to.String()}
+		stateSwitchCounterVec.With(labels).Inc()
+	}
+
+	if settings.IsSuccessful == nil {
+		settings.IsSuccessful = func(err error) bool {
+			switch status.Code(err) {
+			case codes.Unavailable, codes.DeadlineExceeded, codes.Internal, codes.Unknown, codes.DataLoss:
+				return false
+			}
+			return true
+		}
+	}
+
+	return gobreaker.NewCircuitBreaker[struct{}](settings)
+}
@@ -0,0 +1,151 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package grpc
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	grpc_retry "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/retry"
+	"github.com/sony/gobreaker/v2"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// failingHealthServer fails the first calls with the code, or blocks until
+// the call is cancelled if block is set
+type failingHealthServer struct {
+	grpc_health_v1.UnimplementedHealthServer
+	calls    atomic.Int32
+	failures int32
+	code     codes.Code
+	block    bool
+}
+
+func (s *failingHealthServer) Check(ctx context.Context, _ *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	if s.block {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	if s.calls.Add(1) <= s.failures {
+		return nil, status.Error(s.code, "failed")
+	}
+	return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING}, nil
+}
+
+func newTestClient(t *testing.T, srv grpc_health_v1.HealthServer, opts ...ClientOption) grpc_health_v1.HealthClient {
+	listener := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(s, srv)
+	go s.Serve(listener) // nolint: errcheck
+	t.Cleanup(s.Stop)
+
+	opts = append(opts, WithDialOptions(grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return listener.DialContext(ctx)
+	})))
+	conn, err := NewClient("passthrough:///bufnet", opts...)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return grpc_health_v1.NewHealthClient(conn)
+}
+
+func testCheck(client grpc_health_v1.HealthClient) error {
+	_, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	return err
+}
+
+func TestClientRetry(t *testing.T) {
+	fastRetry := RetryPolicy{Max: 3, Backoff: grpc_retry.BackoffLinear(time.Millisecond)}
+
+	t.Run("default codes", func(t *testing.T) {
+		srv := &failingHealthServer{failures: 2, code: codes.Unavailable}
+		require.NoError(t, testCheck(newTestClient(t, srv, WithRetryPolicy(fastRetry))))
+		require.EqualValues(t, 3, srv.calls.Load())
+	})
+
+	t.Run("not idempotent", func(t *testing.T) {
+		policy := fastRetry
+		policy.Codes = []codes.Code{codes.Aborted}
+		srv := &failingHealthServer{failures: 2, code: codes.Aborted}
+		err := testCheck(newTestClient(t, srv, WithRetryPolicy(policy)))
+		require.Equal(t, codes.Aborted, status.Code(err))
+		require.EqualValues(t, 1, srv.calls.Load())
+	})
+
+	t.Run("idempotent", func(t *testing.T) {
+		policy := fastRetry
+		policy.Codes = []codes.Code{codes.Aborted}
+		policy.Idempotent = true
+		srv := &failingHealthServer{failures: 2, code: codes.Aborted}
+		require.NoError(t, testCheck(newTestClient(t, srv, WithRetryPolicy(policy))))
+		require.EqualValues(t, 3, srv.calls.Load())
+	})
+
+	t.Run("per method", func(t *testing.T) {
+		srv := &failingHealthServer{failures: 2, code: codes.Unavailable}
+		client := newTestClient(t, srv,
+			WithRetryPolicy(fastRetry),
+			WithMethodRetryPolicy("/grpc.health.v1.Health/*", RetryPolicy{}),
+		)
+		require.Equal(t, codes.Unavailable, status.Code(testCheck(client)))
+		require.EqualValues(t, 1, srv.calls.Load())
+	})
+}
+
+func TestClientTimeout(t *testing.T) {
+	srv := &failingHealthServer{block: true}
+	client := newTestClient(t, srv,
+		WithTimeout(time.Hour),
+		WithMethodTimeout("/grpc.health.v1.Health/Check", 50*time.Millisecond),
+	)
+
+	start := time.Now()
+	require.Equal(t, codes.DeadlineExceeded, status.Code(testCheck(client)))
+	require.Less(t, time.Since(start), time.Second)
+}
+
+func TestClientCircuitBreaker(t *testing.T) {
+	srv := &failingHealthServer{failures: 100, code: codes.NotFound}
+	client := newTestClient(t, srv,
+		WithRetryPolicy(RetryPolicy{}),
+		WithCircuitBreaker(gobreaker.Settings{
+			Name:    "test",
+			Timeout: time.Hour,
+			ReadyToTrip: func(counts gobreaker.Counts) bool {
+				return counts.ConsecutiveFailures >= 2
+			},
+		}),
+	)
+
+	// client errors do not count as failures
+	for range 3 {
+		require.Equal(t, codes.NotFound, status.Code(testCheck(client)))
+	}
+
+	srv.code = codes.Internal
+	for range 2 {
+		require.Equal(t, codes.Internal, status.Code(testCheck(client)))
+	}
+	err := testCheck(client)
+	require.Equal(t, codes.Unavailable, status.Code(err))
+	require.Contains(t, err.Error(), ErrCircuitBroken.Error())
+	require.EqualValues(t, 5, srv.calls.Load())
+}
+
+func TestClientTarget(t *testing.T) {
+	cfg := newClientConfig(nil)
+	require.Equal(t, "orders:3001", cfg.target("orders:3001"))
+	require.Empty(t, cfg.extraDialOptions())
+
+	cfg = newClientConfig([]ClientOption{WithRoundRobin()})
+	require.Equal(t, "dns:///orders:3001", cfg.target("orders:3001"))
+	require.Equal(t, "passthrough:///orders:3001", cfg.target("passthrough:///orders:3001"))
+	require.Len(t, cfg.extraDialOptions(), 1)
+}
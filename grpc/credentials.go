@@ -0,0 +1,33 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package grpc
+
+import (
+	"context"
+
+	"github.com/pace/bricks/http/security"
+)
+
+// TokenCredentials are per-RPC credentials that authenticate the calls with
+// the bearer token returned by the function, e.g. a token of the service
+// itself, see WithPerRPCCredentials. Calls with a token in the context, see
+// security.GetTokenFromContext, keep using that token. The token is only sent
+// over secure connections.
+type TokenCredentials func(ctx context.Context) (string, error)
+
+// GetRequestMetadata implements credentials.PerRPCCredentials
+func (c TokenCredentials) GetRequestMetadata(ctx context.Context, _ ...string) (map[string]string, error) {
+	if _, ok := security.GetTokenFromContext(ctx); ok {
+		return nil, nil
+	}
+	token, err := c(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{MetadataKeyBearerToken: token}, nil
+}
+
+// RequireTransportSecurity implements credentials.PerRPCCredentials
+func (c TokenCredentials) RequireTransportSecurity() bool {
+	return true
+}
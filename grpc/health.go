@@ -0,0 +1,90 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/pace/bricks/maintenance/health"
+	"github.com/pace/bricks/maintenance/health/servicehealthcheck"
+)
+
+// Interval in which watched health states are checked for changes
+const healthWatchInterval = time.Second
+
+// healthServer implements the gRPC health service. The overall state (empty
+// service name) is the readiness check, like /health/readiness. The
+// registered health checks are available as services by their name.
+type healthServer struct {
+	grpc_health_v1.UnimplementedHealthServer
+}
+
+// AuthFuncOverride lets health checks pass without authorization, see
+// grpc_auth.ServiceAuthFuncOverride.
+func (s *healthServer) AuthFuncOverride(ctx context.Context, _ string) (context.Context, error) {
+	return ctx, nil
+}
+
+func (s *healthServer) Check(ctx context.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	state := healthState(ctx, req.GetService())
+	if state == grpc_health_v1.HealthCheckResponse_SERVICE_UNKNOWN {
+		return nil, status.Errorf(codes.NotFound, "unknown service %q", req.GetService())
+	}
+	return &grpc_health_v1.HealthCheckResponse{Status: state}, nil
+}
+
+func (s *healthServer) List(ctx context.Context, _ *grpc_health_v1.HealthListRequest) (*grpc_health_v1.HealthListResponse, error) {
+	services := map[string]*grpc_health_v1.HealthCheckResponse{
+		"": {Status: healthState(ctx, "")},
+	}
+	for name := range servicehealthcheck.HealthCheckResults() {
+		services[name] = &grpc_health_v1.HealthCheckResponse{Status: healthState(ctx, name)}
+	}
+	return &grpc_health_v1.HealthListResponse{Statuses: services}, nil
+}
+
+func (s *healthServer) Watch(req *grpc_health_v1.HealthCheckRequest, stream grpc_health_v1.Health_WatchServer) error {
+	ctx := stream.Context()
+	ticker := time.NewTicker(healthWatchInterval)
+	defer ticker.Stop()
+
+	last := grpc_health_v1.HealthCheckResponse_ServingStatus(-1)
+	for {
+		if state := healthState(ctx, req.GetService()); state != last {
+			if err := stream.Send(&grpc_health_v1.HealthCheckResponse{Status: state}); err != nil {
+				return err
+			}
+			last = state
+		}
+
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func healthState(ctx context.Context, service string) grpc_health_v1.HealthCheckResponse_ServingStatus {
+	if service == "" {
+		if health.Ready(ctx) {
+			return grpc_health_v1.HealthCheckResponse_SERVING
+		}
+		return grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	}
+
+	result, ok := servicehealthcheck.HealthCheckResults()[service]
+	switch {
+	case !ok:
+		return grpc_health_v1.HealthCheckResponse_SERVICE_UNKNOWN
+	case result.State == servicehealthcheck.Err:
+		return grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	default:
+		return grpc_health_v1.HealthCheckResponse_SERVING
+	}
+}
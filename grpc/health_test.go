@@ -0,0 +1,76 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package grpc
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/pace/bricks/maintenance/health"
+	"github.com/pace/bricks/maintenance/health/servicehealthcheck"
+)
+
+func TestHealthServer(t *testing.T) {
+	ctx := context.Background()
+	s := &healthServer{}
+
+	var failing atomic.Bool
+	servicehealthcheck.RegisterHealthCheckFunc("grpc-test", func(ctx context.Context) servicehealthcheck.HealthCheckResult {
+		if failing.Load() {
+			return servicehealthcheck.HealthCheckResult{State: servicehealthcheck.Err, Msg: "failing"}
+		}
+		return servicehealthcheck.HealthCheckResult{State: servicehealthcheck.Ok}
+	}, servicehealthcheck.UseInterval(10*time.Millisecond))
+
+	check := func(service string) grpc_health_v1.HealthCheckResponse_ServingStatus {
+		resp, err := s.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: service})
+		require.NoError(t, err)
+		return resp.Status
+	}
+
+	require.Eventually(t, func() bool {
+		return check("grpc-test") == grpc_health_v1.HealthCheckResponse_SERVING
+	}, time.Second, 10*time.Millisecond)
+	require.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, check(""))
+
+	list, err := s.List(ctx, &grpc_health_v1.HealthListRequest{})
+	require.NoError(t, err)
+	require.Contains(t, list.Statuses, "")
+	require.Contains(t, list.Statuses, "grpc-test")
+
+	_, err = s.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: "unknown"})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	// failing health checks don't affect the overall state
+	failing.Store(true)
+	require.Eventually(t, func() bool {
+		return check("grpc-test") == grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	}, time.Second, 10*time.Millisecond)
+	require.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, check(""))
+	failing.Store(false)
+	require.Eventually(t, func() bool {
+		return check("grpc-test") == grpc_health_v1.HealthCheckResponse_SERVING
+	}, time.Second, 10*time.Millisecond)
+
+	// the readiness check does
+	health.SetCustomReadinessCheck(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	defer health.SetCustomReadinessCheck(func(w http.ResponseWriter, r *http.Request) {})
+	require.Equal(t, grpc_health_v1.HealthCheckResponse_NOT_SERVING, check(""))
+}
+
+func TestHealthServerAuth(t *testing.T) {
+	ctx := context.WithValue(context.Background(), struct{}{}, "value")
+	authCtx, err := (&healthServer{}).AuthFuncOverride(ctx, "/grpc.health.v1.Health/Check")
+	require.NoError(t, err)
+	require.Equal(t, ctx, authCtx)
+}
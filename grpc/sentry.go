@@ -0,0 +1,66 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package grpc
+
+import (
+	"context"
+
+	"github.com/getsentry/sentry-go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/pace/bricks/maintenance/tracing/sampling"
+)
+
+const sentryClientOperation = "grpc.client"
+
+// sentryUnaryClientInterceptor traces calls with a sentry span and reports
+// errors, like grpc_sentry.UnaryClientInterceptor. The span is propagated
+// using sampling.SentryTraceHeader and sampling.SentryBaggageHeader, so that
+// deferred transactions don't force downstream services to sample.
+func sentryUnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx, hub, span := startSentryClientSpan(ctx, method)
+		defer span.Finish()
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		if err != nil {
+			hub.CaptureException(err)
+		}
+		return err
+	}
+}
+
+// sentryStreamClientInterceptor is the stream variant of
+// sentryUnaryClientInterceptor.
+func sentryStreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		ctx, hub, span := startSentryClientSpan(ctx, method)
+		defer span.Finish()
+
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			hub.CaptureException(err)
+		}
+		return cs, err
+	}
+}
+
+func startSentryClientSpan(ctx context.Context, method string) (context.Context, *sentry.Hub, *sentry.Span) {
+	hub := sentry.GetHubFromContext(ctx)
+	if hub == nil {
+		hub = sentry.CurrentHub().Clone()
+		ctx = sentry.SetHubOnContext(ctx, hub)
+	}
+
+	span := sentry.StartSpan(ctx, sentryClientOperation, sentry.WithDescription(method))
+	span.SetData("grpc.request.method", method)
+
+	md, ok := metadata.FromOutgoingContext(span.Context())
+	if !ok {
+		md = metadata.MD{}
+	}
+	md.Set(sentry.SentryTraceHeader, sampling.SentryTraceHeader(span))
+	md.Set(sentry.SentryBaggageHeader, sampling.SentryBaggageHeader(span))
+	return metadata.NewOutgoingContext(span.Context(), md), hub, span
+}
@@ -0,0 +1,58 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package grpc
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/pace/bricks/maintenance/tracing/sampling"
+)
+
+// metadataHealthServer records the metadata of the last call
+type metadataHealthServer struct {
+	grpc_health_v1.UnimplementedHealthServer
+	md metadata.MD
+}
+
+func (s *metadataHealthServer) Check(ctx context.Context, _ *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	s.md, _ = metadata.FromIncomingContext(ctx)
+	return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING}, nil
+}
+
+func TestClientSentryHeaders(t *testing.T) {
+	p := sampling.NewPolicy(0, sampling.WithRule("sampled", 1), sampling.WithErrors())
+	sentryClient, err := sentry.NewClient(sentry.ClientOptions{
+		EnableTracing: true,
+		TracesSampler: p.SentrySampler(),
+	})
+	require.NoError(t, err)
+	hubCtx := sentry.SetHubOnContext(context.Background(), sentry.NewHub(sentryClient, sentry.NewScope()))
+
+	srv := &metadataHealthServer{}
+	client := newTestClient(t, srv)
+	check := func(name string) (trace, baggage string) {
+		span := sentry.StartTransaction(hubCtx, name)
+		defer span.Finish()
+		_, err := client.Check(span.Context(), &grpc_health_v1.HealthCheckRequest{})
+		require.NoError(t, err)
+		require.Len(t, srv.md.Get(sentry.SentryTraceHeader), 1)
+		return srv.md.Get(sentry.SentryTraceHeader)[0], strings.Join(srv.md.Get(sentry.SentryBaggageHeader), ",")
+	}
+
+	trace, baggage := check("sampled")
+	require.True(t, strings.HasSuffix(trace, "-1"))
+	require.Contains(t, baggage, "sentry-sampled=true")
+
+	// deferred transactions leave the decision to the downstream services
+	trace, baggage = check("deferred")
+	require.Len(t, strings.Split(trace, "-"), 2)
+	require.NotContains(t, baggage, "sentry-sampled")
+	require.NotContains(t, baggage, "sentry-sample_rate")
+}
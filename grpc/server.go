@@ -18,22 +18,32 @@ import (
 	"github.com/pace/bricks/http/security"
 	"github.com/pace/bricks/locale"
 	"github.com/pace/bricks/maintenance/errors"
+	"github.com/pace/bricks/maintenance/lifecycle"
 	"github.com/pace/bricks/maintenance/log"
 	"github.com/pace/bricks/maintenance/log/hlog"
+	"github.com/pace/bricks/maintenance/tracing"
 	"github.com/rs/xid"
 	"github.com/rs/zerolog"
 	zlog "github.com/rs/zerolog/log"
 
 	"github.com/caarlos0/env/v11"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/reflection"
 )
 
 var InternalServerError = errors.New("internal server error")
 
 type Config struct {
 	Address string `env:"GRPC_ADDR" envDefault:":3001"`
+	// Registers the grpc.health.v1 service reporting the readiness and
+	// health checks
+	EnableHealth bool `env:"GRPC_ENABLE_HEALTH"`
+	// Registers the server reflection service
+	EnableReflection bool `env:"GRPC_ENABLE_REFLECTION"`
 }
 
 type AuthBackend interface {
@@ -41,11 +51,15 @@ type AuthBackend interface {
 	AuthorizeUnary(ctx context.Context) (context.Context, error)
 }
 
+// ListenAndServe serves the server on the listener configured using
+// environment variables. The server is registered to be stopped gracefully by
+// lifecycle.WaitForShutdown.
 func ListenAndServe(gs *grpc.Server) error {
 	listener, err := Listener()
 	if err != nil {
 		return err
 	}
+	lifecycle.RegisterGRPCServer(gs)
 	log.Logger().Info().Str("addr", listener.Addr().String()).Msg("Starting grpc server ...")
 	err = gs.Serve(listener)
 	if err != nil {
@@ -71,7 +85,18 @@ func Listener() (net.Listener, error) {
 func Server(ab AuthBackend, logger grpc_logging.Logger) *grpc.Server {
 	serverMetrics := grpc_prometheus.NewServerMetrics()
 
-	myServer := grpc.NewServer(
+	var cfg Config
+	if err := env.Parse(&cfg); err != nil {
+		log.Fatalf("Failed to parse grpc server environment: %v", err)
+	}
+
+	creds, err := ServerCredentials()
+	if err != nil {
+		log.Fatalf("Failed to load grpc server credentials: %v", err)
+	}
+
+	opts := []grpc.ServerOption{
+		grpc.Creds(creds),
 		grpc.ChainStreamInterceptor(
 			grpc_sentry.StreamServerInterceptor(),
 			grpc_logging.StreamServerInterceptor(logger),
@@ -105,6 +130,7 @@ func Server(ab AuthBackend, logger grpc_logging.Logger) *grpc.Server {
 				return err
 			},
 			func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+				defer func() { err = statusError(err) }()
 				defer errors.HandleWithCtx(stream.Context(), "GRPC "+info.FullMethod)
 				err = InternalServerError // default in case of a panic
 				err = handler(srv, stream)
@@ -142,6 +168,7 @@ func Server(ab AuthBackend, logger grpc_logging.Logger) *grpc.Server {
 				return
 			},
 			func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+				defer func() { err = statusError(err) }()
 				defer errors.HandleWithCtx(ctx, "GRPC "+info.FullMethod)
 				err = InternalServerError // default in case of a panic
 				resp, err = handler(ctx, req)
@@ -149,7 +176,20 @@ func Server(ab AuthBackend, logger grpc_logging.Logger) *grpc.Server {
 			},
 			grpc_auth.UnaryServerInterceptor(ab.AuthorizeUnary),
 		),
-	)
+	}
+
+	// continue the traces of the clients, see TRACING_MODE
+	if tracing.OTelEnabled() {
+		opts = append(opts, grpc.StatsHandler(otelgrpc.NewServerHandler()))
+	}
+
+	myServer := grpc.NewServer(opts...)
+	if cfg.EnableHealth {
+		grpc_health_v1.RegisterHealthServer(myServer, &healthServer{})
+	}
+	if cfg.EnableReflection {
+		reflection.Register(myServer)
+	}
 
 	return myServer
 }
@@ -0,0 +1,114 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package grpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pberrors "github.com/pace/bricks/maintenance/errors"
+)
+
+// Domain of the ErrorInfo details, the name of the service inferred from the
+// jaeger service name, service name or executable name
+var errorDomain = func() string {
+	for _, name := range []string{
+		os.Getenv("JAEGER_SERVICE_NAME"),
+		os.Getenv("SERVICE_NAME"),
+		filepath.Base(os.Args[0]),
+	} {
+		if name != "" {
+			return name
+		}
+	}
+	return ""
+}()
+
+// statusError converts errors returned by handlers into gRPC status errors.
+// BricksErrors are mapped by their HTTP status, their code, title and detail
+// are passed as ErrorInfo detail of the domain of the service. Panics result
+// in codes.Internal. Other errors are returned unchanged.
+func statusError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	var be *pberrors.BricksError
+	switch {
+	case errors.As(err, &be):
+		msg := be.Title()
+		if msg == "" {
+			msg = be.Code()
+		}
+		st := status.New(codeFromHTTPStatus(be.Status()), msg)
+
+		info := &errdetails.ErrorInfo{Reason: be.Code(), Domain: errorDomain, Metadata: make(map[string]string)}
+		if be.Title() != "" {
+			info.Metadata["title"] = be.Title()
+		}
+		if be.Detail() != "" {
+			info.Metadata["detail"] = be.Detail()
+		}
+		if withDetails, err := st.WithDetails(info); err == nil {
+			st = withDetails
+		}
+		return st.Err()
+	case errors.Is(err, InternalServerError):
+		return status.Error(codes.Internal, err.Error())
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	}
+
+	return err
+}
+
+// codeFromHTTPStatus maps HTTP status codes to gRPC codes, the inverse of
+// https://github.com/googleapis/googleapis/blob/master/google/rpc/code.proto
+func codeFromHTTPStatus(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusOK:
+		return codes.OK
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.Aborted
+	case http.StatusPreconditionFailed:
+		return codes.FailedPrecondition
+	case http.StatusRequestedRangeNotSatisfiable:
+		return codes.OutOfRange
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case 499: // client closed request
+		return codes.Canceled
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	}
+
+	switch {
+	case httpStatus >= 400 && httpStatus < 500:
+		return codes.FailedPrecondition
+	case httpStatus >= 500 && httpStatus < 600:
+		return codes.Internal
+	}
+	return codes.Unknown
+}
@@ -0,0 +1,72 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pberrors "github.com/pace/bricks/maintenance/errors"
+)
+
+func TestStatusError(t *testing.T) {
+	require.NoError(t, statusError(nil))
+
+	err := fmt.Errorf("other")
+	require.Equal(t, err, statusError(err))
+
+	err = status.Error(codes.NotFound, "not found")
+	require.Equal(t, err, statusError(err))
+
+	require.Equal(t, codes.Internal, status.Code(statusError(InternalServerError)))
+	require.Equal(t, codes.DeadlineExceeded, status.Code(statusError(context.DeadlineExceeded)))
+	require.Equal(t, codes.Canceled, status.Code(statusError(fmt.Errorf("wrapped: %w", context.Canceled))))
+}
+
+func TestStatusErrorBricksError(t *testing.T) {
+	err := fmt.Errorf("wrapped: %w", pberrors.NewBricksError(
+		pberrors.WithStatus(http.StatusNotFound),
+		pberrors.WithCode("ORDER_NOT_FOUND"),
+		pberrors.WithTitle("Order not found"),
+		pberrors.WithDetail("The order 42 does not exist"),
+	))
+
+	st := status.Convert(statusError(err))
+	require.Equal(t, codes.NotFound, st.Code())
+	require.Equal(t, "Order not found", st.Message())
+	require.Len(t, st.Details(), 1)
+	info := st.Details()[0].(*errdetails.ErrorInfo)
+	require.Equal(t, "ORDER_NOT_FOUND", info.Reason)
+	require.Equal(t, errorDomain, info.Domain)
+	require.NotEmpty(t, info.Domain)
+	require.Equal(t, "Order not found", info.Metadata["title"])
+	require.Equal(t, "The order 42 does not exist", info.Metadata["detail"])
+
+	st = status.Convert(statusError(pberrors.NewBricksError(pberrors.WithCode("FAILED"))))
+	require.Equal(t, codes.Unknown, st.Code())
+	require.Equal(t, "FAILED", st.Message())
+}
+
+func TestCodeFromHTTPStatus(t *testing.T) {
+	for httpStatus, code := range map[int]codes.Code{
+		http.StatusBadRequest:          codes.InvalidArgument,
+		http.StatusUnauthorized:        codes.Unauthenticated,
+		http.StatusForbidden:           codes.PermissionDenied,
+		http.StatusConflict:            codes.Aborted,
+		http.StatusTooManyRequests:     codes.ResourceExhausted,
+		http.StatusTeapot:              codes.FailedPrecondition,
+		http.StatusInternalServerError: codes.Internal,
+		http.StatusBadGateway:          codes.Internal,
+		http.StatusServiceUnavailable:  codes.Unavailable,
+		0:                              codes.Unknown,
+	} {
+		require.Equal(t, code, codeFromHTTPStatus(httpStatus), httpStatus)
+	}
+}
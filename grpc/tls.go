@@ -0,0 +1,290 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package grpc
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/caarlos0/env/v11"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/peer"
+
+	"github.com/pace/bricks/maintenance/log"
+)
+
+// ServerTLSConfig is the TLS configuration of the server, it is parsed from
+// the environment by ServerCredentials.
+type ServerTLSConfig struct {
+	// Certificate and key of the server, without the server is insecure
+	Cert string `env:"GRPC_TLS_CERT"`
+	Key  string `env:"GRPC_TLS_KEY"`
+	// CA to verify the certificates of the clients
+	CA string `env:"GRPC_TLS_CA"`
+	// Requires the clients to authenticate using a certificate signed by the
+	// CA (mutual TLS)
+	ClientAuth bool `env:"GRPC_TLS_CLIENT_AUTH"`
+	// Interval in which the files are checked for changes
+	ReloadInterval time.Duration `env:"GRPC_TLS_RELOAD_INTERVAL" envDefault:"30s"`
+}
+
+// ClientTLSConfig is the TLS configuration of clients, it is parsed from the
+// environment by ClientCredentials.
+type ClientTLSConfig struct {
+	// Enables TLS, it is enabled implicitly by a certificate or CA
+	Enabled bool `env:"GRPC_CLIENT_TLS"`
+	// Certificate and key presented to the server (mutual TLS)
+	Cert string `env:"GRPC_CLIENT_TLS_CERT"`
+	Key  string `env:"GRPC_CLIENT_TLS_KEY"`
+	// CA to verify the server, defaults to the system roots
+	CA string `env:"GRPC_CLIENT_TLS_CA"`
+	// Overrides the server name verified by clients
+	ServerName string `env:"GRPC_CLIENT_TLS_SERVER_NAME"`
+	// Interval in which the files are checked for changes
+	ReloadInterval time.Duration `env:"GRPC_TLS_RELOAD_INTERVAL" envDefault:"30s"`
+}
+
+// ServerCredentials returns the transport credentials of the server
+// configured using environment variables. Without certificate the connections
+// are insecure. With CA the certificates presented by clients are verified,
+// see ClientIdentityFromContext. With client auth the clients need to
+// present one (mutual TLS). Changed files are reloaded for new connections.
+func ServerCredentials() (credentials.TransportCredentials, error) {
+	var cfg ServerTLSConfig
+	if err := env.Parse(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse grpc tls environment: %w", err)
+	}
+	if (cfg.Cert == "") != (cfg.Key == "") {
+		return nil, errors.New("GRPC_TLS_CERT and GRPC_TLS_KEY need to be set together")
+	}
+	if cfg.ClientAuth && (cfg.Cert == "" || cfg.CA == "") {
+		return nil, errors.New("GRPC_TLS_CLIENT_AUTH requires GRPC_TLS_CERT and GRPC_TLS_CA")
+	}
+	if cfg.Cert == "" {
+		return insecure.NewCredentials(), nil
+	}
+
+	certs, err := newCertReloader(cfg.Cert, cfg.Key, cfg.CA, cfg.ReloadInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	return credentials.NewTLS(&tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			cert, pool := certs.get()
+			clientAuth := tls.NoClientCert
+			switch {
+			case cfg.ClientAuth:
+				clientAuth = tls.RequireAndVerifyClientCert
+			case pool != nil:
+				clientAuth = tls.VerifyClientCertIfGiven
+			}
+			return &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*cert},
+				ClientCAs:    pool,
+				ClientAuth:   clientAuth,
+				NextProtos:   []string{"h2"},
+			}, nil
+		},
+	}), nil
+}
+
+// ClientCredentials returns the transport credentials of clients configured
+// using environment variables. Without TLS enabled, certificate or CA the
+// connections are insecure. Without CA the server is verified using the
+// system roots. With certificate it is presented to the server (mutual TLS).
+// Changed files are reloaded for new connections.
+func ClientCredentials() (credentials.TransportCredentials, error) {
+	var cfg ClientTLSConfig
+	if err := env.Parse(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse grpc client tls environment: %w", err)
+	}
+	if (cfg.Cert == "") != (cfg.Key == "") {
+		return nil, errors.New("GRPC_CLIENT_TLS_CERT and GRPC_CLIENT_TLS_KEY need to be set together")
+	}
+	if !cfg.Enabled && cfg.Cert == "" && cfg.CA == "" {
+		return insecure.NewCredentials(), nil
+	}
+
+	certs, err := newCertReloader(cfg.Cert, cfg.Key, cfg.CA, cfg.ReloadInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsCfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: cfg.ServerName,
+	}
+	if cfg.Cert != "" {
+		tlsCfg.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			cert, _ := certs.get()
+			return cert, nil
+		}
+	}
+	if cfg.CA != "" {
+		// The verification is done by VerifyConnection, to use the
+		// reloaded CA
+		tlsCfg.InsecureSkipVerify = true
+		tlsCfg.VerifyConnection = func(cs tls.ConnectionState) error {
+			_, pool := certs.get()
+			return verifyServer(cs, pool)
+		}
+	}
+
+	return credentials.NewTLS(tlsCfg), nil
+}
+
+func verifyServer(cs tls.ConnectionState, roots *x509.CertPool) error {
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("server did not present a certificate")
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range cs.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := cs.PeerCertificates[0].Verify(x509.VerifyOptions{
+		DNSName:       cs.ServerName,
+		Roots:         roots,
+		Intermediates: intermediates,
+	})
+	return err
+}
+
+// ClientIdentity is the identity of a client that authenticated using mutual
+// TLS.
+type ClientIdentity struct {
+	// Verified certificate of the client
+	Certificate *x509.Certificate
+}
+
+// CommonName returns the common name of the subject of the certificate.
+func (id ClientIdentity) CommonName() string {
+	return id.Certificate.Subject.CommonName
+}
+
+// DNSNames returns the DNS names of the certificate.
+func (id ClientIdentity) DNSNames() []string {
+	return id.Certificate.DNSNames
+}
+
+// URIs returns the URIs of the certificate, e.g. SPIFFE IDs.
+func (id ClientIdentity) URIs() []*url.URL {
+	return id.Certificate.URIs
+}
+
+// ClientIdentityFromContext returns the identity of the client of the call,
+// if it authenticated using mutual TLS. It is available to the AuthBackend.
+func ClientIdentityFromContext(ctx context.Context) (ClientIdentity, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ClientIdentity{}, false
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return ClientIdentity{}, false
+	}
+	return ClientIdentity{Certificate: info.State.VerifiedChains[0][0]}, true
+}
+
+// certReloader holds the certificate and CA pool, and reloads them if the
+// files changed. The files are checked at most once per interval, when the
+// certificate is needed for a handshake.
+type certReloader struct {
+	certFile, keyFile, caFile string
+	interval                  time.Duration
+
+	mx        sync.Mutex
+	cert      *tls.Certificate // nil if not configured
+	pool      *x509.CertPool   // nil if not configured
+	modTimes  [3]int64         // of cert, key and CA, in unix nanoseconds
+	lastCheck time.Time
+}
+
+func newCertReloader(certFile, keyFile, caFile string, interval time.Duration) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile, caFile: caFile, interval: interval}
+	modTimes, err := r.stat()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.load(modTimes); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// get returns the current certificate and CA pool, after reloading them if
+// the files changed
+func (r *certReloader) get() (*tls.Certificate, *x509.CertPool) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+
+	if time.Since(r.lastCheck) < r.interval {
+		return r.cert, r.pool
+	}
+	r.lastCheck = time.Now()
+
+	modTimes, err := r.stat()
+	if err == nil && modTimes != r.modTimes {
+		err = r.load(modTimes)
+		if err == nil {
+			log.Logger().Info().Msg("reloaded grpc tls certificates")
+		}
+	}
+	if err != nil {
+		log.Logger().Warn().Err(err).Msg("failed to reload grpc tls certificates, using the previous ones")
+	}
+
+	return r.cert, r.pool
+}
+
+func (r *certReloader) stat() ([3]int64, error) {
+	var modTimes [3]int64
+	for i, file := range []string{r.certFile, r.keyFile, r.caFile} {
+		if file == "" {
+			continue
+		}
+		// follows symlinks, e.g. of mounted kubernetes secrets
+		info, err := os.Stat(file)
+		if err != nil {
+			return modTimes, fmt.Errorf("failed to check grpc tls file: %w", err)
+		}
+		modTimes[i] = info.ModTime().UnixNano()
+	}
+	return modTimes, nil
+}
+
+func (r *certReloader) load(modTimes [3]int64) error {
+	var cert *tls.Certificate
+	if r.certFile != "" {
+		c, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load grpc tls certificate: %w", err)
+		}
+		cert = &c
+	}
+
+	var pool *x509.CertPool
+	if r.caFile != "" {
+		pem, err := os.ReadFile(r.caFile)
+		if err != nil {
+			return fmt.Errorf("failed to load grpc tls CA: %w", err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("failed to load grpc tls CA: no certificates in %q", r.caFile)
+		}
+	}
+
+	r.cert, r.pool, r.modTimes, r.lastCheck = cert, pool, modTimes, time.Now()
+	return nil
+}
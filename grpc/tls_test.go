@@ -0,0 +1,258 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package grpc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/pace/bricks/http/security"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	dir  string
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	ca := &testCA{cert: cert, key: key, dir: t.TempDir()}
+	writePEM(t, ca.path("ca.pem"), "CERTIFICATE", der)
+	return ca
+}
+
+func (ca *testCA) path(name string) string {
+	return filepath.Join(ca.dir, name)
+}
+
+// issue writes a certificate and key signed by the CA to name.pem and
+// name-key.pem
+func (ca *testCA) issue(t *testing.T, name, commonName string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	writePEM(t, ca.path(name+"-key.pem"), "EC PRIVATE KEY", keyDER)
+	writePEM(t, ca.path(name+".pem"), "CERTIFICATE", der)
+}
+
+func writePEM(t *testing.T, file, typ string, der []byte) {
+	prev, err := os.Stat(file)
+	require.NoError(t, os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0o600))
+
+	// make sure the modification time changes on coarse file systems
+	if err == nil {
+		modTime := prev.ModTime().Add(time.Second)
+		require.NoError(t, os.Chtimes(file, modTime, modTime))
+	}
+}
+
+func setServerTLSEnv(t *testing.T, ca *testCA, clientAuth bool) {
+	t.Setenv("GRPC_TLS_CERT", ca.path("server.pem"))
+	t.Setenv("GRPC_TLS_KEY", ca.path("server-key.pem"))
+	t.Setenv("GRPC_TLS_CA", ca.path("ca.pem"))
+	t.Setenv("GRPC_TLS_CLIENT_AUTH", strconv.FormatBool(clientAuth))
+	t.Setenv("GRPC_TLS_RELOAD_INTERVAL", "0s")
+}
+
+func setClientTLSEnv(t *testing.T, ca *testCA, name string) {
+	t.Setenv("GRPC_CLIENT_TLS_CA", ca.path("ca.pem"))
+	t.Setenv("GRPC_CLIENT_TLS_CERT", "")
+	t.Setenv("GRPC_CLIENT_TLS_KEY", "")
+	if name != "" {
+		t.Setenv("GRPC_CLIENT_TLS_CERT", ca.path(name+".pem"))
+		t.Setenv("GRPC_CLIENT_TLS_KEY", ca.path(name+"-key.pem"))
+	}
+	t.Setenv("GRPC_TLS_RELOAD_INTERVAL", "0s")
+}
+
+// startTLSServer starts a health server that records the identity of the
+// last client
+func startTLSServer(t *testing.T, creds credentials.TransportCredentials) (string, <-chan ClientIdentity) {
+	identities := make(chan ClientIdentity, 1)
+	s := grpc.NewServer(grpc.Creds(creds), grpc.UnaryInterceptor(
+		func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			if id, ok := ClientIdentityFromContext(ctx); ok {
+				identities <- id
+			}
+			return handler(ctx, req)
+		},
+	))
+	grpc_health_v1.RegisterHealthServer(s, health.NewServer())
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go s.Serve(listener) // nolint: errcheck
+	t.Cleanup(s.Stop)
+
+	_, port, err := net.SplitHostPort(listener.Addr().String())
+	require.NoError(t, err)
+	return "localhost:" + port, identities
+}
+
+func check(t *testing.T, addr string, creds credentials.TransportCredentials) error {
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	return err
+}
+
+func TestMutualTLS(t *testing.T) {
+	ca := newTestCA(t)
+	ca.issue(t, "server", "server")
+	ca.issue(t, "client", "client-a")
+
+	setServerTLSEnv(t, ca, true)
+	serverCreds, err := ServerCredentials()
+	require.NoError(t, err)
+	addr, identities := startTLSServer(t, serverCreds)
+
+	setClientTLSEnv(t, ca, "client")
+	clientCreds, err := ClientCredentials()
+	require.NoError(t, err)
+	require.NoError(t, check(t, addr, clientCreds))
+	require.Equal(t, "client-a", (<-identities).CommonName())
+
+	// the changed certificate is used for new connections
+	ca.issue(t, "client", "client-b")
+	require.NoError(t, check(t, addr, clientCreds))
+	require.Equal(t, "client-b", (<-identities).CommonName())
+
+	// clients without certificate are rejected
+	setClientTLSEnv(t, ca, "")
+	clientCreds, err = ClientCredentials()
+	require.NoError(t, err)
+	require.Error(t, check(t, addr, clientCreds))
+
+	// servers signed by other CAs are rejected
+	other := newTestCA(t)
+	setClientTLSEnv(t, ca, "client")
+	t.Setenv("GRPC_CLIENT_TLS_CA", other.path("ca.pem"))
+	clientCreds, err = ClientCredentials()
+	require.NoError(t, err)
+	require.Error(t, check(t, addr, clientCreds))
+}
+
+func TestTLSWithoutClientAuth(t *testing.T) {
+	ca := newTestCA(t)
+	ca.issue(t, "server", "server")
+	ca.issue(t, "client", "client-a")
+
+	setServerTLSEnv(t, ca, false)
+	serverCreds, err := ServerCredentials()
+	require.NoError(t, err)
+	addr, identities := startTLSServer(t, serverCreds)
+
+	// clients without certificate are accepted
+	setClientTLSEnv(t, ca, "")
+	clientCreds, err := ClientCredentials()
+	require.NoError(t, err)
+	require.NoError(t, check(t, addr, clientCreds))
+	require.Empty(t, identities)
+
+	// the certificates of clients are verified nevertheless
+	setClientTLSEnv(t, ca, "client")
+	clientCreds, err = ClientCredentials()
+	require.NoError(t, err)
+	require.NoError(t, check(t, addr, clientCreds))
+	require.Equal(t, "client-a", (<-identities).CommonName())
+}
+
+func TestTLSConfigInvalid(t *testing.T) {
+	t.Setenv("GRPC_TLS_CERT", "cert.pem")
+	t.Setenv("GRPC_TLS_KEY", "")
+	_, err := ServerCredentials()
+	require.Error(t, err)
+
+	t.Setenv("GRPC_TLS_KEY", "missing.pem")
+	_, err = ServerCredentials()
+	require.Error(t, err)
+
+	// client auth needs a CA
+	t.Setenv("GRPC_TLS_CERT", "")
+	t.Setenv("GRPC_TLS_KEY", "")
+	t.Setenv("GRPC_TLS_CLIENT_AUTH", "true")
+	_, err = ServerCredentials()
+	require.Error(t, err)
+
+	// the server variables don't configure clients
+	t.Setenv("GRPC_CLIENT_TLS_CERT", "cert.pem")
+	_, err = ClientCredentials()
+	require.Error(t, err)
+}
+
+func TestTokenCredentials(t *testing.T) {
+	creds := TokenCredentials(func(context.Context) (string, error) {
+		return "service-token", nil
+	})
+	require.True(t, creds.RequireTransportSecurity())
+
+	md, err := creds.GetRequestMetadata(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{MetadataKeyBearerToken: "service-token"}, md)
+
+	// tokens of the context take precedence
+	ctx := security.ContextWithToken(context.Background(), security.TokenString("user-token"))
+	md, err = creds.GetRequestMetadata(ctx)
+	require.NoError(t, err)
+	require.Empty(t, md)
+
+	failing := TokenCredentials(func(context.Context) (string, error) {
+		return "", errors.New("no token")
+	})
+	_, err = failing.GetRequestMetadata(context.Background())
+	require.Error(t, err)
+}
@@ -0,0 +1,322 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+// Package idempotency implements a middleware that makes mutating requests
+// idempotent using the Idempotency-Key header, see
+// https://datatracker.ietf.org/doc/draft-ietf-httpapi-idempotency-key-header/
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/pace/bricks/http/jsonapi/runtime"
+	"github.com/pace/bricks/http/oauth2"
+	"github.com/pace/bricks/maintenance/log"
+	"github.com/pace/bricks/pkg/cache"
+	"github.com/pace/bricks/pkg/lock"
+)
+
+const (
+	// HeaderKey is the header of the requests containing the key.
+	HeaderKey = "Idempotency-Key"
+	// HeaderReplayed is set on responses that are replayed.
+	HeaderReplayed = "Idempotent-Replayed"
+
+	// maximum length of a key
+	maxKeyLength = 255
+	// ttl of the locks of keys in flight, they are kept up while the request
+	// is handled
+	lockTTL = time.Minute
+)
+
+const (
+	// DefaultTTL is the default duration for which responses are recorded.
+	DefaultTTL = 24 * time.Hour
+	// DefaultMaxBodySize is the default maximum size of request bodies.
+	DefaultMaxBodySize = 1 << 20
+	// DefaultMaxResponseSize is the default maximum size of recorded response
+	// bodies.
+	DefaultMaxResponseSize = 1 << 20
+)
+
+var (
+	ErrInvalidKey = errors.New("idempotency key must have 1 to 255 characters")
+	ErrInFlight   = errors.New("a request with the same idempotency key is in progress")
+	ErrKeyReused  = errors.New("idempotency key was used for a different request")
+	ErrBodyTooBig = errors.New("request body is too large")
+)
+
+// ScopeFunc returns the scope of the keys of the request. Keys of different
+// scopes are independent. Requests with an empty scope are not handled.
+type ScopeFunc func(r *http.Request) string
+
+// DefaultScope scopes keys per oauth2 client and user, see oauth2.ClientID and
+// oauth2.UserID. Requests without token are not handled.
+func DefaultScope(r *http.Request) string {
+	clientID, _ := oauth2.ClientID(r.Context())
+	userID, _ := oauth2.UserID(r.Context())
+	if clientID == "" && userID == "" {
+		return ""
+	}
+	return "client:" + clientID + "\x00user:" + userID
+}
+
+// Option configures the Middleware.
+type Option func(*middleware)
+
+// WithTTL sets the duration for which responses are recorded, it defaults to
+// DefaultTTL.
+func WithTTL(ttl time.Duration) Option {
+	return func(m *middleware) {
+		m.ttl = ttl
+	}
+}
+
+// WithScopeFunc sets the function that returns the scope of the keys, it
+// defaults to DefaultScope.
+func WithScopeFunc(scope ScopeFunc) Option {
+	return func(m *middleware) {
+		m.scope = scope
+	}
+}
+
+// WithMaxBodySize sets the maximum size of the bodies of requests with a key,
+// it defaults to DefaultMaxBodySize. The bodies are read to detect reused
+// keys, larger ones are answered with 413.
+func WithMaxBodySize(size int64) Option {
+	return func(m *middleware) {
+		m.maxBodySize = size
+	}
+}
+
+// WithMaxResponseSize sets the maximum size of the recorded response bodies,
+// it defaults to DefaultMaxResponseSize. Larger responses are not recorded,
+// so requests with the same key are handled again.
+func WithMaxResponseSize(size int) Option {
+	return func(m *middleware) {
+		m.maxResponseSize = size
+	}
+}
+
+// WithLocker sets the locker used to detect concurrent requests with the same
+// key. It defaults to a locker that only detects them within the process, so
+// a distributed locker needs to be used if the cache is shared by multiple
+// processes, e.g. redis.NewLocker of pkg/lock/redis.
+func WithLocker(locker lock.Locker) Option {
+	return func(m *middleware) {
+		m.locker = locker
+	}
+}
+
+type middleware struct {
+	cache           *cache.Typed[record]
+	locker          lock.Locker
+	scope           ScopeFunc
+	ttl             time.Duration
+	maxBodySize     int64
+	maxResponseSize int
+	next            http.Handler
+}
+
+// record is a response recorded for a key.
+type record struct {
+	Fingerprint string      `json:"fingerprint"`
+	Status      int         `json:"status"`
+	Header      http.Header `json:"header"`
+	Body        []byte      `json:"body"`
+}
+
+// Middleware makes POST, PUT, PATCH and DELETE requests with an
+// Idempotency-Key header idempotent. The first response for a key is
+// recorded in the cache and replayed for subsequent requests with the same
+// key, method, URL and body. Concurrent requests with the same key are
+// answered with 409, requests reusing a key for a different request with
+// 422, requests with bodies larger than WithMaxBodySize with 413. Responses
+// with status 5xx are not recorded, so that the request can be retried,
+// neither are responses larger than WithMaxResponseSize. The context of the
+// request is cancelled if the lock of the key is lost, see WithLocker.
+//
+// The middleware needs to run after the oauth2 middleware to scope the keys
+// by client and user.
+func Middleware(c cache.Cache, opts ...Option) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		m := &middleware{
+			cache:           cache.NewTyped[record](c, cache.JSONCodec, cache.WithVersion("idempotency:v1")),
+			locker:          newLocalLocker(),
+			scope:           DefaultScope,
+			ttl:             DefaultTTL,
+			maxBodySize:     DefaultMaxBodySize,
+			maxResponseSize: DefaultMaxResponseSize,
+			next:            next,
+		}
+		for _, opt := range opts {
+			opt(m)
+		}
+		return m
+	}
+}
+
+func (m *middleware) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key, ok := r.Header[http.CanonicalHeaderKey(HeaderKey)]
+	if !ok || !isMutating(r.Method) {
+		m.next.ServeHTTP(w, r)
+		return
+	}
+	if len(key) != 1 || key[0] == "" || len(key[0]) > maxKeyLength {
+		runtime.WriteError(w, http.StatusBadRequest, ErrInvalidKey)
+		return
+	}
+	scope := m.scope(r)
+	if scope == "" {
+		m.next.ServeHTTP(w, r)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, m.maxBodySize))
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		runtime.WriteError(w, http.StatusRequestEntityTooLarge, ErrBodyTooBig)
+		return
+	}
+	if err != nil {
+		runtime.WriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	ctx := r.Context()
+	cacheKey := hash(scope, key[0])
+	fingerprint := hash(r.Method, r.URL.RequestURI(), string(body))
+
+	if m.replay(ctx, w, cacheKey, fingerprint) {
+		return
+	}
+
+	lockCtx, release, err := m.locker.NewLock("idempotency:"+cacheKey, lockTTL).AcquireAndKeepUp(ctx)
+	if err != nil {
+		log.Req(r).Warn().Err(err).Msg("failed to lock idempotency key, request is not idempotent")
+		m.next.ServeHTTP(w, r)
+		return
+	}
+	if lockCtx == nil {
+		runtime.WriteError(w, http.StatusConflict, ErrInFlight)
+		return
+	}
+	defer release()
+
+	// the request may have completed before the lock was acquired
+	if m.replay(ctx, w, cacheKey, fingerprint) {
+		return
+	}
+
+	rec := &recorder{ResponseWriter: w, status: http.StatusOK, limit: m.maxResponseSize}
+	m.next.ServeHTTP(rec, r.WithContext(lockCtx))
+	if !rec.wroteHeader {
+		rec.header = w.Header().Clone()
+	}
+	if rec.status >= 500 || rec.failed {
+		return
+	}
+	if rec.tooLarge {
+		log.Req(r).Debug().Int("limit", m.maxResponseSize).Msg("idempotent response is too large to be recorded")
+		return
+	}
+	err = m.cache.Put(context.WithoutCancel(ctx), cacheKey, record{
+		Fingerprint: fingerprint,
+		Status:      rec.status,
+		Header:      rec.header,
+		Body:        rec.body.Bytes(),
+	}, m.ttl)
+	if err != nil {
+		log.Req(r).Warn().Err(err).Msg("failed to record idempotent response")
+	}
+}
+
+// Writes the recorded response for the key, if any, and reports whether the
+// request was answered.
+func (m *middleware) replay(ctx context.Context, w http.ResponseWriter, key, fingerprint string) bool {
+	rec, _, err := m.cache.Get(ctx, key)
+	if errors.Is(err, cache.ErrNotFound) {
+		return false
+	}
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("failed to get idempotent response")
+		return false
+	}
+	if rec.Fingerprint != fingerprint {
+		runtime.WriteError(w, http.StatusUnprocessableEntity, ErrKeyReused)
+		return true
+	}
+
+	h := w.Header()
+	for k, v := range rec.Header {
+		h[k] = v
+	}
+	h.Set(HeaderReplayed, "true")
+	w.WriteHeader(rec.Status)
+	if _, err := w.Write(rec.Body); err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("failed to write idempotent response")
+	}
+	return true
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// Returns the hex encoded SHA-256 of the values, separated by zero bytes.
+func hash(values ...string) string {
+	h := sha256.New()
+	for _, v := range values {
+		h.Write([]byte(v)) // nolint: errcheck
+		h.Write([]byte{0}) // nolint: errcheck
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// recorder records the response while writing it.
+type recorder struct {
+	http.ResponseWriter
+	status      int
+	header      http.Header
+	body        bytes.Buffer
+	wroteHeader bool
+	failed      bool // the response was not written completely
+	limit       int  // maximum size of the recorded body
+	tooLarge    bool // the body exceeded the limit and is not recorded
+}
+
+func (w *recorder) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.status = status
+		w.header = w.ResponseWriter.Header().Clone()
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recorder) Write(data []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	n, err := w.ResponseWriter.Write(data)
+	if !w.tooLarge && w.body.Len()+n > w.limit {
+		w.tooLarge = true
+		w.body = bytes.Buffer{}
+	}
+	if !w.tooLarge {
+		w.body.Write(data[:n])
+	}
+	w.failed = w.failed || err != nil
+	return n, err
+}
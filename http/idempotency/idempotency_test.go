@@ -0,0 +1,192 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package idempotency
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pace/bricks/http/oauth2"
+	"github.com/pace/bricks/pkg/cache"
+)
+
+type tokenIntrospecter struct{}
+
+// The token is the user id.
+func (tokenIntrospecter) IntrospectToken(ctx context.Context, token string) (*oauth2.IntrospectResponse, error) {
+	return &oauth2.IntrospectResponse{Active: true, ClientID: "client", UserID: token}, nil
+}
+
+type testHandler struct {
+	calls   atomic.Int32
+	status  int
+	blocked chan struct{} // if set, requests block until it is closed
+}
+
+func (h *testHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n := h.calls.Add(1)
+	if h.blocked != nil {
+		<-h.blocked
+	}
+	body, _ := io.ReadAll(r.Body)
+	w.Header().Set("X-Call", fmt.Sprint(n))
+	w.WriteHeader(h.status)
+	_, _ = fmt.Fprintf(w, "call %d: %s", n, body)
+}
+
+func newServer(h http.Handler, opts ...Option) http.Handler {
+	return oauth2.NewMiddleware(tokenIntrospecter{}).Handler(Middleware(cache.InMemory(), opts...)(h))
+}
+
+func request(h http.Handler, method, user, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/orders", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+user)
+	if key != "" {
+		req.Header.Set(HeaderKey, key)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestMiddlewareReplay(t *testing.T) {
+	h := &testHandler{status: http.StatusCreated}
+	s := newServer(h)
+
+	first := request(s, http.MethodPost, "alice", "k1", `{"a":1}`)
+	require.Equal(t, http.StatusCreated, first.Code)
+	require.Equal(t, `call 1: {"a":1}`, first.Body.String())
+	require.Empty(t, first.Header().Get(HeaderReplayed))
+
+	second := request(s, http.MethodPost, "alice", "k1", `{"a":1}`)
+	require.Equal(t, http.StatusCreated, second.Code)
+	require.Equal(t, `call 1: {"a":1}`, second.Body.String())
+	require.Equal(t, "1", second.Header().Get("X-Call"))
+	require.Equal(t, "true", second.Header().Get(HeaderReplayed))
+	require.EqualValues(t, 1, h.calls.Load())
+
+	// keys are scoped per user
+	rec := request(s, http.MethodPost, "bob", "k1", `{"a":1}`)
+	require.Equal(t, `call 2: {"a":1}`, rec.Body.String())
+
+	// other keys are independent
+	rec = request(s, http.MethodPost, "alice", "k2", `{"a":1}`)
+	require.Equal(t, `call 3: {"a":1}`, rec.Body.String())
+}
+
+func TestMiddlewareKeyReused(t *testing.T) {
+	h := &testHandler{status: http.StatusCreated}
+	s := newServer(h)
+
+	request(s, http.MethodPost, "alice", "k1", `{"a":1}`)
+	rec := request(s, http.MethodPost, "alice", "k1", `{"a":2}`)
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	require.Contains(t, rec.Body.String(), ErrKeyReused.Error())
+
+	rec = request(s, http.MethodPatch, "alice", "k1", `{"a":1}`)
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	require.EqualValues(t, 1, h.calls.Load())
+}
+
+func TestMiddlewareInFlight(t *testing.T) {
+	h := &testHandler{status: http.StatusCreated, blocked: make(chan struct{})}
+	s := newServer(h)
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- request(s, http.MethodPost, "alice", "k1", `{}`) }()
+	require.Eventually(t, func() bool { return h.calls.Load() == 1 }, time.Second, time.Millisecond)
+
+	rec := request(s, http.MethodPost, "alice", "k1", `{}`)
+	require.Equal(t, http.StatusConflict, rec.Code)
+	require.Contains(t, rec.Body.String(), ErrInFlight.Error())
+
+	close(h.blocked)
+	require.Equal(t, http.StatusCreated, (<-done).Code)
+
+	rec = request(s, http.MethodPost, "alice", "k1", `{}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	require.Equal(t, "true", rec.Header().Get(HeaderReplayed))
+	require.EqualValues(t, 1, h.calls.Load())
+}
+
+func TestMiddlewareServerError(t *testing.T) {
+	h := &testHandler{status: http.StatusBadGateway}
+	s := newServer(h)
+
+	request(s, http.MethodPost, "alice", "k1", `{}`)
+	h.status = http.StatusCreated
+	rec := request(s, http.MethodPost, "alice", "k1", `{}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	require.EqualValues(t, 2, h.calls.Load())
+}
+
+func TestMiddlewareSizeLimits(t *testing.T) {
+	h := &testHandler{status: http.StatusCreated}
+	s := newServer(h, WithMaxBodySize(8), WithMaxResponseSize(12))
+
+	rec := request(s, http.MethodPost, "alice", "k1", `{"a":"too large"}`)
+	require.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	require.Contains(t, rec.Body.String(), ErrBodyTooBig.Error())
+	require.EqualValues(t, 0, h.calls.Load())
+
+	// small responses are recorded
+	request(s, http.MethodPost, "alice", "k2", `{}`)
+	rec = request(s, http.MethodPost, "alice", "k2", `{}`)
+	require.Equal(t, "true", rec.Header().Get(HeaderReplayed))
+	require.EqualValues(t, 1, h.calls.Load())
+
+	// large responses are not recorded
+	first := request(s, http.MethodPost, "alice", "k3", `{"a":1}`)
+	require.Equal(t, `call 2: {"a":1}`, first.Body.String())
+	rec = request(s, http.MethodPost, "alice", "k3", `{"a":1}`)
+	require.Equal(t, `call 3: {"a":1}`, rec.Body.String())
+	require.Empty(t, rec.Header().Get(HeaderReplayed))
+}
+
+func TestMiddlewareNotHandled(t *testing.T) {
+	h := &testHandler{status: http.StatusOK}
+	s := newServer(h)
+
+	// without key
+	request(s, http.MethodPost, "alice", "", `{}`)
+	request(s, http.MethodPost, "alice", "", `{}`)
+	// safe methods
+	request(s, http.MethodGet, "alice", "k1", ``)
+	request(s, http.MethodGet, "alice", "k1", ``)
+	require.EqualValues(t, 4, h.calls.Load())
+
+	// without token
+	s = Middleware(cache.InMemory())(h)
+	for range 2 {
+		req := httptest.NewRequest(http.MethodPost, "/orders", nil)
+		req.Header.Set(HeaderKey, "k1")
+		s.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	require.EqualValues(t, 6, h.calls.Load())
+}
+
+func TestMiddlewareInvalidKey(t *testing.T) {
+	h := &testHandler{status: http.StatusOK}
+	s := newServer(h)
+
+	rec := request(s, http.MethodPost, "alice", strings.Repeat("k", 256), `{}`)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	req := httptest.NewRequest(http.MethodPost, "/orders", nil)
+	req.Header.Set("Authorization", "Bearer alice")
+	req.Header.Add(HeaderKey, "k1")
+	req.Header.Add(HeaderKey, "k2")
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.EqualValues(t, 0, h.calls.Load())
+}
@@ -0,0 +1,75 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pace/bricks/pkg/lock"
+)
+
+var _ lock.Locker = (*localLocker)(nil)
+
+// localLocker creates locks that are held within the process only. The ttl
+// of the locks is ignored, they are held until released.
+type localLocker struct {
+	mx   sync.Mutex
+	held map[string]struct{}
+}
+
+func newLocalLocker() *localLocker {
+	return &localLocker{held: make(map[string]struct{})}
+}
+
+func (l *localLocker) NewLock(name string, _ time.Duration) lock.Lock {
+	return &localLock{locker: l, name: name}
+}
+
+type localLock struct {
+	locker *localLocker
+	name   string
+}
+
+func (l *localLock) Acquire(context.Context) (bool, error) {
+	l.locker.mx.Lock()
+	defer l.locker.mx.Unlock()
+	if _, ok := l.locker.held[l.name]; ok {
+		return false, nil
+	}
+	l.locker.held[l.name] = struct{}{}
+	return true, nil
+}
+
+func (l *localLock) AcquireWait(ctx context.Context) error {
+	for {
+		if ok, _ := l.Acquire(ctx); ok {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return lock.ErrCouldNotLock
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func (l *localLock) AcquireAndKeepUp(ctx context.Context) (context.Context, context.CancelFunc, error) {
+	if ok, _ := l.Acquire(ctx); !ok {
+		return nil, nil, nil
+	}
+	lockCtx, cancel := context.WithCancel(ctx)
+	var once sync.Once
+	return lockCtx, func() {
+		cancel()
+		once.Do(func() { _ = l.Release(ctx) })
+	}, nil
+}
+
+func (l *localLock) Release(context.Context) error {
+	l.locker.mx.Lock()
+	defer l.locker.mx.Unlock()
+	delete(l.locker.held, l.name)
+	return nil
+}
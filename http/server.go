@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/caarlos0/env/v11"
+	"github.com/pace/bricks/maintenance/lifecycle"
 	"github.com/pace/bricks/maintenance/log"
 )
 
@@ -44,9 +45,10 @@ func parseConfig() {
 }
 
 // Server returns a http.Server configured using environment variables,
-// following https://12factor.net/.
+// following https://12factor.net/. Start it with ListenAndServe to shut it
+// down gracefully by lifecycle.WaitForShutdown.
 func Server(handler http.Handler) *http.Server {
-	return &http.Server{
+	s := &http.Server{
 		Addr:           cfg.addrOrPort(),
 		Handler:        handler,
 		ReadTimeout:    cfg.ReadTimeout,
@@ -55,6 +57,16 @@ func Server(handler http.Handler) *http.Server {
 		IdleTimeout:    cfg.IdleTimeout,
 		ErrorLog:       golog.New(log.Logger(), "[http.Server] ", 0),
 	}
+	return s
+}
+
+// ListenAndServe serves the server on its address. The server is registered
+// to be shut down gracefully by lifecycle.WaitForShutdown. Like
+// http.Server.ListenAndServe it returns http.ErrServerClosed once it is shut
+// down.
+func ListenAndServe(s *http.Server) error {
+	lifecycle.RegisterHTTPServer(s)
+	return s.ListenAndServe()
 }
 
 // Environment returns the name of the current server environment
@@ -7,6 +7,7 @@ package transport
 func NewDefaultTransportChain() *RoundTripperChain {
 	return Chain(
 		&ExternalDependencyRoundTripper{},
+		&TracingRoundTripper{},
 		NewDefaultRetryRoundTripper(),
 		&LoggingRoundTripper{},
 		&LocaleRoundTripper{},
@@ -22,6 +23,7 @@ func NewDefaultTransportChain() *RoundTripperChain {
 func NewDefaultTransportChainWithExternalName(name string) *RoundTripperChain {
 	return Chain(
 		&ExternalDependencyRoundTripper{name: name},
+		&TracingRoundTripper{},
 		NewDefaultRetryRoundTripper(),
 		&LoggingRoundTripper{},
 		&LocaleRoundTripper{},
@@ -0,0 +1,66 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package transport
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/pace/bricks/maintenance/tracing"
+)
+
+// TracingRoundTripper implements a chainable round tripper for tracing. If
+// OpenTelemetry is enabled, see tracing.OTelEnabled, every request is traced
+// with a client span, that is propagated using the W3C trace context and
+// baggage headers. Otherwise requests are passed on unchanged.
+type TracingRoundTripper struct {
+	transport http.RoundTripper
+}
+
+// Transport returns the RoundTripper to make HTTP requests
+func (l *TracingRoundTripper) Transport() http.RoundTripper {
+	return l.transport
+}
+
+// SetTransport sets the RoundTripper to make HTTP requests
+func (l *TracingRoundTripper) SetTransport(rt http.RoundTripper) {
+	l.transport = rt
+}
+
+// RoundTrip executes a single HTTP transaction via Transport()
+func (l *TracingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !tracing.OTelEnabled() {
+		return l.Transport().RoundTrip(req)
+	}
+
+	ctx, span := tracing.Tracer().Start(req.Context(), req.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", req.Method),
+			attribute.String("server.address", req.URL.Hostname()),
+			attribute.String("url.full", req.URL.Redacted()),
+		),
+	)
+	defer span.End()
+
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := l.Transport().RoundTrip(req.WithContext(ctx))
+	switch {
+	case err != nil:
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	default:
+		span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+		if resp.StatusCode >= http.StatusBadRequest {
+			span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+		}
+	}
+
+	return resp, err
+}
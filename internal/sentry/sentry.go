@@ -7,6 +7,8 @@ import (
 	"strings"
 
 	"github.com/getsentry/sentry-go"
+
+	"github.com/pace/bricks/maintenance/tracing/sampling"
 )
 
 func init() {
@@ -35,12 +37,23 @@ func init() {
 		}
 	}
 
-	err := sentry.Init(sentry.ClientOptions{
+	policy, err := sampling.FromEnv(tracesSampleRate)
+	if err != nil {
+		log.Fatalf("failed to parse sampling policy: %v", err)
+	}
+	sampling.SetDefault(policy)
+
+	err = sentry.Init(sentry.ClientOptions{
 		Dsn:              os.Getenv("SENTRY_DSN"),
 		Environment:      os.Getenv("ENVIRONMENT"),
 		EnableTracing:    enableTracing,
 		TracesSampleRate: tracesSampleRate,
+		TracesSampler:    policy.SentrySampler(),
 		BeforeSendTransaction: func(event *sentry.Event, hint *sentry.EventHint) *sentry.Event {
+			if !policy.KeepSentryTransaction(event) {
+				return nil
+			}
+
 			// Drop request body.
 			if event.Request != nil {
 				event.Request.Data = ""
@@ -14,6 +14,9 @@ import (
 
 const errorsPkg = "github.com/pace/bricks/maintenance/errors"
 
+// directory of the SQL migrations, relative to the control command
+const migrationsDir = "migrations"
+
 // CommandOptions are applied when generating the different
 // microservice commands
 type CommandOptions struct {
@@ -58,6 +61,7 @@ func Commands(path string, options CommandOptions) {
 			generateDaemonMain(code, cmdName)
 		} else {
 			generateControlMain(code, cmdName)
+			generateMigrations(dir)
 		}
 		_, err = f.WriteString(copyright())
 		if err != nil {
@@ -74,9 +78,11 @@ func Commands(path string, options CommandOptions) {
 func generateDaemonMain(f *jen.File, cmdName string) {
 	httpPkg := "github.com/pace/bricks/http"
 	logPkg := "github.com/pace/bricks/maintenance/log"
+	lifecyclePkg := "github.com/pace/bricks/maintenance/lifecycle"
 	trancing := "github.com/pace/bricks/maintenance/tracing"
 
 	f.ImportAlias(httpPkg, "pacehttp")
+	f.ImportAlias("errors", "stderrors")
 	f.Anon(trancing)
 	f.Func().Id("main").Params().BlockFunc(func(g *jen.Group) {
 		g.Defer().Qual(errorsPkg, "HandleWithCtx").Call(jen.Qual("context", "Background").Call(), jen.Lit(cmdName))
@@ -88,14 +94,91 @@ func generateDaemonMain(f *jen.File, cmdName string) {
 			jen.Id("s").Dot("Addr"),
 		).Dot("Msg").Call(jen.Lit(fmt.Sprintf("Starting %s ...", cmdName)))
 
-		g.Qual(logPkg, "Fatal").Call(jen.Id("s").Dot("ListenAndServe").Call())
+		// a failed server shuts the service down and exits non-zero,
+		// so that it is restarted instead of considered completed
+		g.Id("serverErr").Op(":=").Make(jen.Chan().Error(), jen.Lit(1))
+		g.Go().Func().Params().Block(
+			jen.Id("err").Op(":=").Qual(httpPkg, "ListenAndServe").Call(jen.Id("s")),
+			jen.If(jen.Op("!").Qual("errors", "Is").Call(jen.Id("err"), jen.Qual("net/http", "ErrServerClosed"))).Block(
+				jen.Id("serverErr").Op("<-").Id("err"),
+				jen.Qual(lifecyclePkg, "Shutdown").Call(),
+			),
+		).Call()
+
+		g.Qual(lifecyclePkg, "WaitForShutdown").Call()
+
+		g.Select().Block(
+			jen.Case(jen.Id("err").Op(":=").Op("<-").Id("serverErr")).Block(
+				jen.Qual(logPkg, "Fatalf").Call(jen.Lit("Server failed: %v"), jen.Id("err")),
+			),
+			jen.Default().Block(),
+		)
 	})
 }
 
 func generateControlMain(f *jen.File, cmdName string) {
-	f.Func().Id("main").Params().Block(
-		jen.Defer().Qual(errorsPkg, "HandleWithCtx").Call(jen.Qual("context", "Background").Call(), jen.Lit(cmdName)),
-		jen.Qual("fmt", "Printf").Call(jen.Lit(cmdName)))
+	logPkg := "github.com/pace/bricks/maintenance/log"
+	postgresPkg := "github.com/pace/bricks/backend/postgres"
+	migratePkg := "github.com/pace/bricks/backend/postgres/migrate"
+
+	f.Comment("//go:embed " + migrationsDir + "/*.sql")
+	f.Var().Id("migrations").Qual("embed", "FS")
+
+	f.Func().Id("main").Params().BlockFunc(func(g *jen.Group) {
+		g.Defer().Qual(errorsPkg, "HandleWithCtx").Call(jen.Qual("context", "Background").Call(), jen.Lit(cmdName))
+		g.Id("ctx").Op(":=").Qual(logPkg, "WithContext").Call(jen.Qual("context", "Background").Call())
+
+		g.If(jen.Len(jen.Qual("os", "Args")).Op("<").Lit(2)).Block(
+			jen.Qual("fmt", "Printf").Call(jen.Lit(fmt.Sprintf("usage: %s migrate [-dry-run] [up | down [steps] | status]\n", cmdName))),
+			jen.Qual("os", "Exit").Call(jen.Lit(2)),
+		)
+
+		g.Switch(jen.Qual("os", "Args").Index(jen.Lit(1))).Block(
+			jen.Case(jen.Lit("migrate")).Block(
+				jen.List(jen.Id("fsys"), jen.Id("err")).Op(":=").Qual("io/fs", "Sub").Call(jen.Id("migrations"), jen.Lit(migrationsDir)),
+				jen.If(jen.Err().Op("!=").Nil()).Block(
+					jen.Qual(logPkg, "Fatal").Call(jen.Err()),
+				),
+				jen.Id("err").Op("=").Qual(migratePkg, "Command").Call(
+					jen.Id("ctx"),
+					jen.Qual(postgresPkg, "NewDB").Call(jen.Id("ctx")),
+					jen.Id("fsys"),
+					jen.Qual("os", "Args").Index(jen.Lit(2).Op(":")),
+				),
+				jen.If(jen.Err().Op("!=").Nil()).Block(
+					jen.Qual(logPkg, "Fatal").Call(jen.Err()),
+				),
+			),
+			jen.Default().Block(
+				jen.Qual("fmt", "Printf").Call(jen.Lit("unknown command %q\n"), jen.Qual("os", "Args").Index(jen.Lit(1))),
+				jen.Qual("os", "Exit").Call(jen.Lit(2)),
+			),
+		)
+	})
+}
+
+// Creates the directory of the migrations embedded into the control command
+// with an initial migration, unless it exists already.
+func generateMigrations(dir string) {
+	dir = filepath.Join(dir, migrationsDir)
+	if _, err := os.Stat(dir); err == nil {
+		return
+	}
+	err := os.MkdirAll(dir, 0o770) // nolint: gosec
+	if err != nil {
+		log.Fatalf("Failed to create dir %s: %v", dir, err)
+	}
+
+	files := map[string]string{
+		"1_init.up.sql":   "-- Initial migration, add the schema of the service here.\n",
+		"1_init.down.sql": "-- Revert the initial migration.\n",
+	}
+	for name, content := range files {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o660) // nolint: gosec
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
 }
 
 // copyright generates copyright statement
@@ -0,0 +1,195 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package failover
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pace/bricks/maintenance/errors"
+	"github.com/pace/bricks/maintenance/log"
+	"github.com/pace/bricks/pkg/lock"
+)
+
+// DefaultRetryInterval is the default interval in which an election tries to
+// acquire the leadership.
+const DefaultRetryInterval = 500 * time.Millisecond
+
+// Election elects a leader among all processes running an election with the
+// same name and locker. The leader is the process holding the lock with the
+// name. Any number of elections can run in a process, they are independent of
+// the readiness probe.
+type Election struct {
+	name          string
+	ttl           time.Duration
+	locker        lock.Locker
+	retryInterval time.Duration
+
+	mx        sync.Mutex
+	leaderCtx context.Context // nil if not the leader
+	changes   []chan bool
+	done      bool
+}
+
+// ElectionOption configures an Election.
+type ElectionOption func(*Election)
+
+// WithRetryInterval sets the interval in which the election tries to acquire
+// the leadership, it defaults to DefaultRetryInterval.
+func WithRetryInterval(interval time.Duration) ElectionOption {
+	return func(e *Election) {
+		e.retryInterval = interval
+	}
+}
+
+// NewElection creates an election identified by the name, which is used as
+// name of the lock. The ttl is passed to the locker, it determines how fast a
+// crashed leader is replaced.
+func NewElection(name string, ttl time.Duration, locker lock.Locker, opts ...ElectionOption) *Election {
+	e := &Election{
+		name:          name,
+		ttl:           ttl,
+		locker:        locker,
+		retryInterval: DefaultRetryInterval,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Run takes part in the election until the context is done. The leadership is
+// given up when Run returns. Run must only be called once.
+func (e *Election) Run(ctx context.Context) error {
+	defer errors.HandleWithCtx(ctx, "election "+e.name)
+
+	logger := log.Ctx(ctx).With().Str("election", e.name).Logger()
+	ctx = logger.WithContext(ctx)
+
+	l := e.locker.NewLock(e.name, e.ttl)
+
+	// Done once the leadership is lost, nil if not the leader
+	var lost <-chan struct{}
+	cancelLock := func() {}
+	defer func() {
+		cancelLock()
+		e.stop()
+	}()
+
+	ticker := time.NewTicker(e.retryInterval)
+	defer ticker.Stop()
+
+	// Whether the result of the next attempt is published even if the
+	// process doesn't become the leader
+	publishNext := true
+
+	for {
+		if lost == nil {
+			lockCtx, cancel, err := l.AcquireAndKeepUp(ctx)
+			switch {
+			case err != nil:
+				logger.Info().Err(err).Msg("failed to acquire the leadership")
+			case lockCtx != nil:
+				logger.Debug().Msg("acquired the leadership")
+				lost, cancelLock = lockCtx.Done(), cancel
+				e.setLeader(lockCtx)
+			}
+			if lost == nil && publishNext {
+				e.publish(false)
+			}
+			publishNext = false
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-lost:
+			logger.Info().Msg("lost the leadership")
+			lost = nil
+			cancelLock()
+			e.mx.Lock()
+			e.leaderCtx = nil
+			e.mx.Unlock()
+			// the loss is published with the result of the next attempt
+			publishNext = true
+		case <-ticker.C:
+		}
+	}
+}
+
+// IsLeader reports whether the process is the leader.
+func (e *Election) IsLeader() bool {
+	e.mx.Lock()
+	defer e.mx.Unlock()
+	return e.leaderCtx != nil
+}
+
+// Context returns a context that is cancelled once the leadership is lost. If
+// the process is not the leader, the context is cancelled already.
+func (e *Election) Context() context.Context {
+	e.mx.Lock()
+	defer e.mx.Unlock()
+	if e.leaderCtx == nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		return ctx
+	}
+	return e.leaderCtx
+}
+
+// Changes returns a channel that receives whether the process is the leader,
+// after the first attempt to acquire the leadership and on every change. The
+// loss of the leadership is received once the following attempt to acquire it
+// finished, use Context to notice the loss immediately. If the receiver is
+// slow, only the latest state is kept. The channel is closed once Run
+// returns.
+func (e *Election) Changes() <-chan bool {
+	e.mx.Lock()
+	defer e.mx.Unlock()
+	ch := make(chan bool, 1)
+	if e.done {
+		close(ch)
+		return ch
+	}
+	e.changes = append(e.changes, ch)
+	return ch
+}
+
+func (e *Election) setLeader(leaderCtx context.Context) {
+	e.mx.Lock()
+	e.leaderCtx = leaderCtx
+	e.mx.Unlock()
+	e.publish(leaderCtx != nil)
+}
+
+func (e *Election) publish(leader bool) {
+	e.mx.Lock()
+	defer e.mx.Unlock()
+	for _, ch := range e.changes {
+		// replace a state that was not received yet
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- leader
+	}
+}
+
+func (e *Election) stop() {
+	e.mx.Lock()
+	wasLeader := e.leaderCtx != nil
+	e.leaderCtx = nil
+	e.mx.Unlock()
+	if wasLeader {
+		e.publish(false)
+	}
+
+	e.mx.Lock()
+	defer e.mx.Unlock()
+	for _, ch := range e.changes {
+		close(ch)
+	}
+	e.changes = nil
+	e.done = true
+}
@@ -0,0 +1,129 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package failover
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pace/bricks/pkg/lock"
+)
+
+// testLocker holds locks in memory. Held locks can be taken away to simulate
+// the loss of a lock.
+// Acquisitions wait while the gate is set.
+type testLocker struct {
+	mx   sync.Mutex
+	held map[string]*context.CancelFunc
+	gate chan struct{}
+}
+
+func (l *testLocker) NewLock(name string, _ time.Duration) lock.Lock {
+	return &testLock{locker: l, name: name}
+}
+
+func (l *testLocker) takeAway(name string) {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+	if cancel, ok := l.held[name]; ok {
+		(*cancel)()
+		delete(l.held, name)
+	}
+}
+
+type testLock struct {
+	lock.Lock
+	locker *testLocker
+	name   string
+}
+
+func (l *testLock) AcquireAndKeepUp(ctx context.Context) (context.Context, context.CancelFunc, error) {
+	l.locker.mx.Lock()
+	gate := l.locker.gate
+	l.locker.mx.Unlock()
+	if gate != nil {
+		select {
+		case <-gate:
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+	}
+
+	l.locker.mx.Lock()
+	defer l.locker.mx.Unlock()
+	if _, ok := l.locker.held[l.name]; ok {
+		return nil, nil, nil
+	}
+	lockCtx, cancel := context.WithCancel(ctx)
+	held := &cancel
+	l.locker.held[l.name] = held
+	return lockCtx, func() {
+		cancel()
+		l.locker.mx.Lock()
+		defer l.locker.mx.Unlock()
+		if l.locker.held[l.name] == held {
+			delete(l.locker.held, l.name)
+		}
+	}, nil
+}
+
+func receive(t *testing.T, ch <-chan bool) bool {
+	t.Helper()
+	select {
+	case leader := <-ch:
+		return leader
+	case <-time.After(time.Second):
+		t.Fatal("no change received")
+		return false
+	}
+}
+
+func TestElection(t *testing.T) {
+	locker := &testLocker{held: make(map[string]*context.CancelFunc)}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	a := NewElection("test", time.Second, locker, WithRetryInterval(10*time.Millisecond))
+	require.False(t, a.IsLeader())
+	require.Error(t, a.Context().Err())
+
+	aChanges := a.Changes()
+	aDone := make(chan error)
+	go func() { aDone <- a.Run(ctx) }()
+	require.True(t, receive(t, aChanges))
+	require.True(t, a.IsLeader())
+	aCtx := a.Context()
+	require.NoError(t, aCtx.Err())
+
+	// elections of other names are independent
+	other := NewElection("other", time.Second, locker)
+	otherChanges := other.Changes()
+	go other.Run(ctx) // nolint: errcheck
+	require.True(t, receive(t, otherChanges))
+
+	b := NewElection("test", time.Second, locker, WithRetryInterval(10*time.Millisecond))
+	bChanges := b.Changes()
+	go b.Run(ctx) // nolint: errcheck
+	require.False(t, receive(t, bChanges))
+	require.False(t, b.IsLeader())
+
+	// a loses the lock, either a or b acquires it again
+	locker.takeAway("test")
+	require.Eventually(t, func() bool { return aCtx.Err() != nil }, time.Second, time.Millisecond)
+	require.Eventually(t, func() bool { return a.IsLeader() != b.IsLeader() }, time.Second, time.Millisecond)
+
+	// the leadership is given up if Run returns
+	cancel()
+	require.ErrorIs(t, <-aDone, context.Canceled)
+	require.False(t, a.IsLeader())
+	var leader, ok bool
+	for leader = range aChanges {
+	}
+	require.False(t, leader)
+	_, ok = <-a.Changes()
+	require.False(t, ok)
+}
@@ -10,10 +10,11 @@ import (
 	"sync"
 	"time"
 
-	"github.com/bsm/redislock"
 	"github.com/pace/bricks/maintenance/errors"
 	"github.com/pace/bricks/maintenance/health"
 	"github.com/pace/bricks/maintenance/log"
+	"github.com/pace/bricks/pkg/lock"
+	redislock "github.com/pace/bricks/pkg/lock/redis"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -29,8 +30,9 @@ const (
 // to deploy a service multiple times but ony one will accept
 // traffic by using the label selector of kubernetes.
 // In order to determine the active, a lock needs to be hold
-// in redis. Hooks can be passed to handle the case of becoming
-// the active or passive.
+// in redis, or using another locker, see WithLocker. Hooks
+// can be passed to handle the case of becoming the active
+// or passive.
 // The readiness probe will report the state (ACTIVE/PASSIVE)
 // of each of the members in the cluster.
 type ActivePassive struct {
@@ -46,9 +48,10 @@ type ActivePassive struct {
 	close          chan struct{}
 	clusterName    string
 	timeToFailover time.Duration
-	locker         *redislock.Client
+	locker         lock.Locker
 
-	stateSetter StateSetter
+	stateSetter      StateSetter
+	noReadinessCheck bool
 
 	// current status of the failover (to show it in the readiness status)
 	state   status
@@ -70,6 +73,26 @@ func WithCustomStateSetter(fn func(ctx context.Context, state string) error) Act
 	}
 }
 
+// WithLocker uses the locker to determine the active, instead of the redis
+// client passed to NewActivePassive, e.g. to use postgres advisory locks.
+func WithLocker(locker lock.Locker) ActivePassiveOption {
+	return func(ap *ActivePassive) error {
+		ap.locker = locker
+
+		return nil
+	}
+}
+
+// WithoutReadinessCheck doesn't report the state in the readiness probe,
+// which allows creating multiple ActivePassive in one process.
+func WithoutReadinessCheck() ActivePassiveOption {
+	return func(ap *ActivePassive) error {
+		ap.noReadinessCheck = true
+
+		return nil
+	}
+}
+
 func WithNoopStateSetter() ActivePassiveOption {
 	return func(ap *ActivePassive) error {
 		ap.stateSetter = &NoopStateSetter{}
@@ -94,14 +117,16 @@ func WithPodStateSetter() ActivePassiveOption {
 // NewActivePassive creates a new active passive cluster
 // identified by the name. The time to fail over determines
 // the frequency of checks performed against redis to
-// keep the active state.
+// keep the active state. The client may be nil if another
+// locker is used, see WithLocker.
 // NOTE: creating multiple ActivePassive in one process
-// is not working correctly as there is only one readiness probe.
+// is not working correctly as there is only one readiness probe,
+// see WithoutReadinessCheck. To only elect a leader, use an
+// Election instead.
 func NewActivePassive(clusterName string, timeToFailover time.Duration, client *redis.Client, opts ...ActivePassiveOption) (*ActivePassive, error) {
 	activePassive := &ActivePassive{
 		clusterName:    clusterName,
 		timeToFailover: timeToFailover,
-		locker:         redislock.New(client),
 	}
 
 	for _, opt := range opts {
@@ -110,6 +135,13 @@ func NewActivePassive(clusterName string, timeToFailover time.Duration, client *
 		}
 	}
 
+	if activePassive.locker == nil {
+		if client == nil {
+			return nil, fmt.Errorf("failed to create locker: no redis client")
+		}
+		activePassive.locker = redislock.NewLocker(redislock.SetRedisClient(client))
+	}
+
 	if activePassive.stateSetter == nil {
 		var err error
 
@@ -120,15 +152,18 @@ func NewActivePassive(clusterName string, timeToFailover time.Duration, client *
 		}
 	}
 
-	health.SetCustomReadinessCheck(activePassive.Handler)
+	if !activePassive.noReadinessCheck {
+		health.SetCustomReadinessCheck(activePassive.Handler)
+	}
 
 	return activePassive, nil
 }
 
-// Run manages distributed lock-based leadership.
+// Run manages distributed lock-based leadership using an Election.
 // This method is designed to continually monitor and maintain the leadership status of the calling pod,
 // ensuring only one active instance holds the lock at a time, while transitioning other instances to passive
-// mode. The handler will try to renew its active status by refreshing the lock periodically.
+// mode. If the lock is lost, the pod becomes undefined until it failed to obtain the lock again. States
+// that could not be set are retried periodically.
 func (a *ActivePassive) Run(ctx context.Context) error {
 	defer errors.HandleWithCtx(ctx, "activepassive failover handler")
 
@@ -145,51 +180,56 @@ func (a *ActivePassive) Run(ctx context.Context) error {
 		}
 	}()
 
-	var lock *redislock.Lock
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	election := NewElection(lockName, a.timeToFailover, a.locker)
+	changes := election.Changes()
+	done := make(chan error, 1)
+	go func() { done <- election.Run(ctx) }()
 
-	// Ticker to try to refresh the lock's TTL before it expires
-	tryRefreshLock := time.NewTicker(a.timeToFailover)
+	// Ticker to retry setting the state of the election
+	retry := time.NewTicker(DefaultRetryInterval)
+	defer retry.Stop()
 
-	// Ticker to try to acquire the lock if in passive or undefined state
-	tryAcquireLock := time.NewTicker(500 * time.Millisecond)
+	// Whether the process is the leader, nil until the first change and after
+	// the loss of the lock
+	var leader *bool
+	// Done once the lock is lost, nil if not the leader
+	var lost <-chan struct{}
 
 	for {
 		select {
-		case <-ctx.Done():
-			return ctx.Err()
+		case err := <-done:
+			return err
 		case <-a.close:
+			cancel()
+			<-done
 			return nil
-		case <-tryRefreshLock.C:
-			if a.getState() == ACTIVE {
-				err := lock.Refresh(ctx, a.timeToFailover, &redislock.Options{
-					RetryStrategy: redislock.LimitRetry(redislock.LinearBackoff(a.timeToFailover/3), 3),
-				})
-				if err != nil {
-					logger.Info().Err(err).Msg("failed to refresh the lock; becoming undefined...")
-					a.becomeUndefined(ctx)
-				}
+		case active, ok := <-changes:
+			if !ok {
+				changes = nil // the election stopped, Run returns
+				continue
+			}
+			if ctx.Err() != nil {
+				continue // the election is stopping
 			}
-		case <-tryAcquireLock.C:
-			if a.getState() != ACTIVE {
-				var err error
-
-				lock, err = a.locker.Obtain(ctx, lockName, a.timeToFailover, &redislock.Options{
-					RetryStrategy: redislock.LimitRetry(redislock.LinearBackoff(a.timeToFailover/3), 3),
-				})
-				if err != nil {
-					if a.getState() != PASSIVE {
-						logger.Info().Err(err).Msg("failed to obtain the lock; becoming passive...")
-						a.becomePassive(ctx)
-					}
-
-					continue
-				}
-
-				logger.Debug().Msg("lock acquired; becoming active...")
-				a.becomeActive(ctx)
-
-				// Reset the refresh ticker to half of the time to failover
-				tryRefreshLock.Reset(a.timeToFailover / 2)
+			leader, lost = &active, nil
+			if active {
+				lost = election.Context().Done()
+			}
+			a.follow(ctx, active)
+		case <-lost:
+			// only the next election result decides, once the attempt to
+			// obtain the lock again finished
+			leader, lost = nil, nil
+			if ctx.Err() == nil {
+				logger.Info().Msg("lost the lock; becoming undefined...")
+				a.becomeUndefined(ctx)
+			}
+		case <-retry.C:
+			if leader != nil && ctx.Err() == nil {
+				a.follow(ctx, *leader)
 			}
 		}
 	}
@@ -234,6 +274,19 @@ func (a *ActivePassive) becomePassive(ctx context.Context) {
 	}
 }
 
+// follow sets the state matching the leadership, if it isn't set already
+func (a *ActivePassive) follow(ctx context.Context, leader bool) {
+	logger := log.Ctx(ctx)
+	switch state := a.getState(); {
+	case leader && state != ACTIVE:
+		logger.Debug().Msg("lock acquired; becoming active...")
+		a.becomeActive(ctx)
+	case !leader && state != PASSIVE:
+		logger.Info().Msg("failed to obtain the lock; becoming passive...")
+		a.becomePassive(ctx)
+	}
+}
+
 func (a *ActivePassive) becomeUndefined(ctx context.Context) {
 	a.setState(ctx, UNDEFINED)
 }
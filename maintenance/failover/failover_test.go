@@ -0,0 +1,89 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package failover
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// steal takes the lock away and holds it for another process
+func (l *testLocker) steal(name string) {
+	l.takeAway(name)
+	l.mx.Lock()
+	defer l.mx.Unlock()
+	cancel := context.CancelFunc(func() {})
+	l.held[name] = &cancel
+}
+
+func TestActivePassive(t *testing.T) {
+	locker := &testLocker{held: make(map[string]*context.CancelFunc)}
+
+	var (
+		mx     sync.Mutex
+		states []string
+		fail   = 1 // number of calls to fail
+	)
+	setState := func(_ context.Context, state string) error {
+		mx.Lock()
+		defer mx.Unlock()
+		if fail > 0 {
+			fail--
+			return errors.New("k8s unavailable")
+		}
+		states = append(states, state)
+		return nil
+	}
+	lastStates := func() []string {
+		mx.Lock()
+		defer mx.Unlock()
+		return append([]string(nil), states...)
+	}
+
+	ap, err := NewActivePassive("test", time.Second, nil,
+		WithLocker(locker), WithCustomStateSetter(setState), WithoutReadinessCheck())
+	require.NoError(t, err)
+	lockName := "activepassive:lock:test"
+
+	// another process is active
+	otherCtx, cancelOther := context.WithCancel(context.Background())
+	defer cancelOther()
+	other := NewElection(lockName, time.Second, locker)
+	otherChanges := other.Changes()
+	go other.Run(otherCtx) // nolint: errcheck
+	require.True(t, receive(t, otherChanges))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error)
+	go func() { done <- ap.Run(ctx) }()
+
+	// the failed passive state is retried
+	require.Eventually(t, func() bool { return ap.getState() == PASSIVE }, 2*time.Second, 10*time.Millisecond)
+	require.Equal(t, []string{"passive"}, lastStates())
+
+	// the process becomes active once the other process stopped
+	cancelOther()
+	require.Eventually(t, func() bool { return ap.getState() == ACTIVE }, 2*time.Second, 10*time.Millisecond)
+
+	// on lock loss the process becomes undefined, and stays undefined until
+	// it failed to obtain the lock again
+	gate := make(chan struct{})
+	locker.mx.Lock()
+	locker.gate = gate
+	locker.mx.Unlock()
+	locker.steal(lockName)
+	require.Eventually(t, func() bool { return ap.getState() == UNDEFINED }, 2*time.Second, 10*time.Millisecond)
+	require.Never(t, func() bool { return ap.getState() != UNDEFINED }, 3*DefaultRetryInterval, 10*time.Millisecond)
+	close(gate)
+	require.Eventually(t, func() bool { return ap.getState() == PASSIVE }, 2*time.Second, 10*time.Millisecond)
+	require.Equal(t, []string{"passive", "active", "undefined", "passive"}, lastStates())
+
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+}
@@ -5,8 +5,10 @@
 package health
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"sync/atomic"
 
 	"github.com/rs/zerolog"
 
@@ -14,15 +16,23 @@ import (
 )
 
 type handler struct {
-	check func(http.ResponseWriter, *http.Request)
+	check     func(http.ResponseWriter, *http.Request)
+	readiness bool
 }
 
-var readinessCheck = &handler{check: liveness}
+var (
+	readinessCheck = &handler{check: liveness, readiness: true}
+	shuttingDown   atomic.Bool
+)
 
 func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	logger := log.Logger()
 	logger.Level(zerolog.DebugLevel)
-	h.check(w, r.WithContext(
+	check := h.check
+	if h.readiness && shuttingDown.Load() {
+		check = notReady
+	}
+	check(w, r.WithContext(
 		log.ContextWithSink(
 			logger.WithContext(r.Context()),
 			log.NewSink(log.Silent()),
@@ -36,6 +46,21 @@ func SetCustomReadinessCheck(check func(http.ResponseWriter, *http.Request)) {
 	readinessCheck.check = check
 }
 
+// SetShuttingDown lets the readiness check fail regardless of the configured
+// check, so that the loadbalancer stops routing requests to the instance while
+// it shuts down. The liveness check is not affected.
+func SetShuttingDown() {
+	shuttingDown.Store(true)
+}
+
+func notReady(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusServiceUnavailable)
+	if _, err := fmt.Fprint(w, "SHUTTING DOWN\n"); err != nil {
+		log.Warnf("could not write output: %s", err)
+	}
+}
+
 func liveness(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/plain")
 	w.WriteHeader(http.StatusOK)
@@ -49,6 +74,40 @@ func HandlerLiveness() http.Handler {
 	return &handler{check: liveness}
 }
 
+// Ready reports whether the readiness check succeeds, i.e. responds with a
+// 2xx status code, see HandlerReadiness.
+func Ready(ctx context.Context) bool {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "/health/readiness", nil)
+	if err != nil {
+		return false
+	}
+	rec := &statusRecorder{header: make(http.Header), status: http.StatusOK}
+	readinessCheck.ServeHTTP(rec, req)
+	return rec.status >= 200 && rec.status < 300
+}
+
+// statusRecorder records the status code of a check and discards the body
+type statusRecorder struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.WriteHeader(http.StatusOK)
+	return len(b), nil
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status, r.wroteHeader = status, true
+	}
+}
+
 // HandlerReadiness returns the readiness handler. This handler can be configured with
 // ReadinessCheck(func(http.ResponseWriter,*http.Request)), the default behavior is a liveness check
 func HandlerReadiness() http.Handler {
@@ -3,6 +3,7 @@
 package health
 
 import (
+	"context"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -41,6 +42,21 @@ func TestHandlerReadiness(t *testing.T) {
 	checkResult(rec, 404, "Err\n", t)
 }
 
+func TestHandlerReadiness_shuttingDown(t *testing.T) {
+	defer shuttingDown.Store(false)
+	SetShuttingDown()
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/health/readiness", nil)
+	HandlerReadiness().ServeHTTP(rec, req)
+	checkResult(rec, 503, "SHUTTING DOWN\n", t)
+
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/health/liveness", nil)
+	HandlerLiveness().ServeHTTP(rec, req)
+	checkResult(rec, 200, "OK\n", t)
+}
+
 func checkResult(rec *httptest.ResponseRecorder, expCode int, expBody string, t *testing.T) {
 	resp := rec.Result()
 	defer resp.Body.Close()
@@ -51,3 +67,21 @@ func checkResult(rec *httptest.ResponseRecorder, expCode int, expBody string, t
 	require.NoError(t, err)
 	require.Equal(t, expBody, string(data))
 }
+
+func TestReady(t *testing.T) {
+	defer SetCustomReadinessCheck(liveness)
+	ctx := context.Background()
+
+	SetCustomReadinessCheck(liveness)
+	require.True(t, Ready(ctx))
+
+	SetCustomReadinessCheck(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	require.False(t, Ready(ctx))
+
+	SetCustomReadinessCheck(liveness)
+	SetShuttingDown()
+	defer shuttingDown.Store(false)
+	require.False(t, Ready(ctx))
+}
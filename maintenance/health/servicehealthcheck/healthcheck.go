@@ -68,6 +68,16 @@ func checksResults(checks *sync.Map) map[string]HealthCheckResult {
 	return results
 }
 
+// HealthCheckResults returns the latest results of all registered health
+// checks, required and optional, by name.
+func HealthCheckResults() map[string]HealthCheckResult {
+	results := checksResults(&requiredChecks)
+	for name, result := range checksResults(&optionalChecks) {
+		results[name] = result
+	}
+	return results
+}
+
 // RegisterHealthCheck registers a required HealthCheck. The name
 // must be unique. If the health check satisfies the Initializable interface, it
 // is initialized before it is added.
@@ -0,0 +1,246 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+// Package lifecycle coordinates the graceful shutdown of a microservice. Once
+// the process receives SIGINT or SIGTERM, the readiness check starts failing.
+// After the pre-stop delay, that gives the loadbalancer time to notice, the
+// registered hooks are run phase by phase: first the servers are drained,
+// then all routines are canceled, then the queue consumers are stopped and
+// finally sentry is flushed. If the shutdown exceeds its timeout, the hooks
+// that did not finish are written to the termination log.
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/caarlos0/env/v11"
+	"github.com/getsentry/sentry-go"
+
+	"github.com/pace/bricks/maintenance/health"
+	"github.com/pace/bricks/maintenance/log"
+	"github.com/pace/bricks/maintenance/terminationlog"
+)
+
+type config struct {
+	// Time to wait after the readiness check started failing before the
+	// servers are drained
+	PreStopDelay time.Duration `env:"SHUTDOWN_PRE_STOP_DELAY" envDefault:"5s"`
+	// Time the complete shutdown may take, excluding flushing
+	Timeout time.Duration `env:"SHUTDOWN_TIMEOUT" envDefault:"25s"`
+	// Time flushing may take, even if the shutdown exceeded its timeout
+	FlushTimeout time.Duration `env:"SHUTDOWN_FLUSH_TIMEOUT" envDefault:"2s"`
+}
+
+var cfg config
+
+func init() {
+	if err := env.Parse(&cfg); err != nil {
+		log.Fatalf("Failed to parse lifecycle environment: %v", err)
+	}
+
+	OnShutdown(PhaseFlush, "sentry", func(ctx context.Context) error {
+		timeout := cfg.FlushTimeout
+		if deadline, ok := ctx.Deadline(); ok {
+			timeout = time.Until(deadline)
+		}
+		if !sentry.Flush(timeout) {
+			return fmt.Errorf("sentry: not all events were flushed")
+		}
+		return nil
+	})
+}
+
+// Phase of the shutdown. The phases are run in the order of their
+// declaration, the hooks of a single phase are run in parallel.
+type Phase int
+
+const (
+	// PhaseServers stops accepting new requests and waits for the in-flight
+	// requests to finish.
+	PhaseServers Phase = iota
+	// PhaseRoutines cancels all routines and waits for them to return.
+	PhaseRoutines
+	// PhaseConsumers stops consuming from queues.
+	PhaseConsumers
+	// PhaseFlush flushes buffered data like sentry events. It is run even if
+	// the shutdown exceeded its timeout.
+	PhaseFlush
+)
+
+func (p Phase) String() string {
+	switch p {
+	case PhaseServers:
+		return "servers"
+	case PhaseRoutines:
+		return "routines"
+	case PhaseConsumers:
+		return "consumers"
+	case PhaseFlush:
+		return "flush"
+	default:
+		return fmt.Sprintf("phase %d", int(p))
+	}
+}
+
+type hook struct {
+	name string
+	fn   func(context.Context) error
+}
+
+var (
+	hooksMx sync.Mutex
+	hooks   = map[Phase][]hook{}
+
+	shutdownCh   = make(chan struct{})
+	shutdownOnce sync.Once
+	coordinating atomic.Bool
+)
+
+// OnShutdown registers a hook that is run in the given phase of the shutdown.
+// The context passed to the hook is done once the shutdown exceeds its
+// timeout, the hook must return then.
+func OnShutdown(phase Phase, name string, fn func(context.Context) error) {
+	hooksMx.Lock()
+	defer hooksMx.Unlock()
+	hooks[phase] = append(hooks[phase], hook{name: name, fn: fn})
+}
+
+// RegisterHTTPServer registers the server to be shut down in PhaseServers.
+func RegisterHTTPServer(s *http.Server) {
+	OnShutdown(PhaseServers, "http server "+s.Addr, s.Shutdown)
+}
+
+// GRPCServer is implemented by *grpc.Server.
+type GRPCServer interface {
+	GracefulStop()
+	Stop()
+}
+
+// RegisterGRPCServer registers the server to be gracefully stopped in
+// PhaseServers. If the shutdown exceeds its timeout, the server is stopped
+// forcefully.
+func RegisterGRPCServer(s GRPCServer) {
+	OnShutdown(PhaseServers, "grpc server", func(ctx context.Context) error {
+		stopped := make(chan struct{})
+		go func() {
+			s.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+			return nil
+		case <-ctx.Done():
+			s.Stop()
+			return ctx.Err()
+		}
+	})
+}
+
+// Shutdown triggers the shutdown without a signal, e.g. because a server
+// failed. WaitForShutdown returns once the shutdown finished.
+func Shutdown() {
+	shutdownOnce.Do(func() {
+		close(shutdownCh)
+	})
+}
+
+// Coordinating returns true once WaitForShutdown was called. Packages that
+// shut down on their own if the process receives a signal, must leave it to
+// the registered hooks instead.
+func Coordinating() bool {
+	return coordinating.Load()
+}
+
+// WaitForShutdown blocks until the process receives SIGINT or SIGTERM or
+// Shutdown is called, and then shuts down gracefully. A second signal received
+// during the shutdown terminates the process immediately.
+func WaitForShutdown() {
+	coordinating.Store(true)
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
+
+	select {
+	case sig := <-c:
+		log.Logger().Info().Str("signal", sig.String()).Msg("received shutdown signal, shutting down")
+	case <-shutdownCh:
+		log.Logger().Info().Msg("shutting down")
+	}
+	signal.Stop(c)
+
+	drain(context.Background())
+}
+
+// Runs the shutdown and writes the unfinished hooks to the termination log if
+// it exceeds its timeout.
+func drain(ctx context.Context) {
+	health.SetShuttingDown()
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+	defer cancel()
+
+	select {
+	case <-time.After(cfg.PreStopDelay):
+	case <-timeoutCtx.Done():
+	}
+
+	var unfinished []string
+	for _, phase := range []Phase{PhaseServers, PhaseRoutines, PhaseConsumers} {
+		unfinished = append(unfinished, runPhase(timeoutCtx, phase)...)
+	}
+
+	flushCtx, cancelFlush := context.WithTimeout(context.WithoutCancel(ctx), cfg.FlushTimeout)
+	defer cancelFlush()
+	runPhase(flushCtx, PhaseFlush)
+
+	if timeoutCtx.Err() != nil {
+		terminationlog.Printf("graceful shutdown exceeded timeout of %s, unfinished: %s",
+			cfg.Timeout, strings.Join(unfinished, ", "))
+		return
+	}
+	log.Logger().Info().Msg("shutdown finished")
+}
+
+// Runs all hooks of the phase in parallel and waits until they finished.
+// Returns the names of the hooks that did not finish before the context was
+// done.
+func runPhase(ctx context.Context, phase Phase) []string {
+	hooksMx.Lock()
+	phaseHooks := append([]hook(nil), hooks[phase]...)
+	hooksMx.Unlock()
+
+	var (
+		wg         sync.WaitGroup
+		mx         sync.Mutex
+		unfinished []string
+	)
+	for _, h := range phaseHooks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			logger := log.Logger().With().Str("phase", phase.String()).Str("hook", h.name).Logger()
+			err := h.fn(ctx)
+			if err == nil {
+				logger.Debug().Msg("shutdown hook finished")
+				return
+			}
+			logger.Warn().Err(err).Msg("shutdown hook failed")
+			if ctx.Err() != nil {
+				mx.Lock()
+				unfinished = append(unfinished, phase.String()+"/"+h.name)
+				mx.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	sort.Strings(unfinished)
+	return unfinished
+}
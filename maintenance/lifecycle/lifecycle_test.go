@@ -0,0 +1,71 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package lifecycle
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pace/bricks/maintenance/health"
+)
+
+func TestDrain_runsPhasesInOrder(t *testing.T) {
+	withHooks(t)
+	cfg.PreStopDelay = 10 * time.Millisecond
+
+	var mx sync.Mutex
+	var order []string
+	record := func(name string) func(context.Context) error {
+		return func(context.Context) error {
+			mx.Lock()
+			defer mx.Unlock()
+			order = append(order, name)
+			return nil
+		}
+	}
+	OnShutdown(PhaseFlush, "flush", record("flush"))
+	OnShutdown(PhaseConsumers, "consumers", record("consumers"))
+	OnShutdown(PhaseRoutines, "routines", record("routines"))
+	OnShutdown(PhaseServers, "readiness", func(ctx context.Context) error {
+		rec := httptest.NewRecorder()
+		health.HandlerReadiness().ServeHTTP(rec, httptest.NewRequest("GET", "/health/readiness", nil))
+		require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		return record("servers")(ctx)
+	})
+
+	drain(context.Background())
+	require.Equal(t, []string{"servers", "routines", "consumers", "flush"}, order)
+}
+
+func TestRunPhase_reportsUnfinishedHooks(t *testing.T) {
+	withHooks(t)
+
+	OnShutdown(PhaseServers, "fast", func(context.Context) error { return nil })
+	OnShutdown(PhaseServers, "slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.Equal(t, []string{"servers/slow"}, runPhase(ctx, PhaseServers))
+}
+
+// Replaces the registered hooks and the config for the duration of the test.
+func withHooks(t *testing.T) {
+	hooksMx.Lock()
+	oldHooks, oldCfg := hooks, cfg
+	hooks = map[Phase][]hook{}
+	hooksMx.Unlock()
+	t.Cleanup(func() {
+		hooksMx.Lock()
+		hooks, cfg = oldHooks, oldCfg
+		hooksMx.Unlock()
+	})
+}
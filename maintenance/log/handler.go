@@ -49,6 +49,8 @@ var requestCompleted = func(r *http.Request, status, size int, duration time.Dur
 	span := sentry.SpanFromContext(ctx)
 	if span != nil {
 		traceID = span.TraceID.String()
+	} else {
+		traceID = TraceIDFromContext(ctx)
 	}
 
 	hlog.FromRequest(r).Info().
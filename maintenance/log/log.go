@@ -18,6 +18,7 @@ import (
 	"github.com/rs/zerolog/log"
 
 	isatty "github.com/mattn/go-isatty"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type config struct {
@@ -99,13 +100,18 @@ func RequestIDFromContext(ctx context.Context) string {
 	return ""
 }
 
-// TraceIDFromContext returns a unique request id or an empty string if there is none
+// TraceIDFromContext returns a unique request id or an empty string if there is none.
+// If there is an OpenTelemetry span in the context, its trace id is returned.
 func TraceIDFromContext(ctx context.Context) string {
 	id, ok := hlog.TraceIDFromCtx(ctx)
 	if ok {
 		return id
 	}
 
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		return sc.TraceID().String()
+	}
+
 	return ""
 }
 
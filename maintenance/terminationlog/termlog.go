@@ -16,6 +16,16 @@ import (
 
 var logFile *os.File
 
+// Printf writes to the termination log without exiting the program. The
+// message is logged as an error as well.
+func Printf(format string, v ...interface{}) {
+	if logFile != nil {
+		fmt.Fprintf(logFile, format, v...)
+	}
+
+	log.Error().Msg(fmt.Sprintf(format, v...))
+}
+
 // Fatalf implements log Fatalf interface
 func Fatalf(format string, v ...interface{}) {
 	if logFile != nil {
@@ -0,0 +1,159 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/caarlos0/env/v11"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/pace/bricks/maintenance/lifecycle"
+	"github.com/pace/bricks/maintenance/log"
+	"github.com/pace/bricks/maintenance/tracing/sampling"
+)
+
+// Tracing modes, see TRACING_MODE.
+const (
+	// ModeSentry creates sentry spans.
+	ModeSentry = "sentry"
+	// ModeOTel creates OpenTelemetry spans, that are exported using OTLP.
+	ModeOTel = "otel"
+)
+
+// Name of the tracer used by the instrumentation of bricks.
+const tracerName = "github.com/pace/bricks"
+
+type config struct {
+	Mode string `env:"TRACING_MODE" envDefault:"sentry"`
+	// Protocol of the OTLP exporter, the other settings are read by the
+	// exporter, e.g. OTEL_EXPORTER_OTLP_ENDPOINT
+	Protocol string `env:"OTEL_EXPORTER_OTLP_PROTOCOL" envDefault:"http/protobuf"`
+	// Exporter of the OpenTelemetry metrics, e.g. of the gRPC
+	// instrumentation, "otlp" or "none"
+	MetricsExporter string `env:"OTEL_METRICS_EXPORTER" envDefault:"none"`
+	// Protocol of the OTLP metrics exporter, only gRPC is supported
+	MetricsProtocol string `env:"OTEL_EXPORTER_OTLP_METRICS_PROTOCOL" envDefault:"grpc"`
+}
+
+var cfg config
+
+func init() {
+	if err := env.Parse(&cfg); err != nil {
+		log.Fatalf("Failed to parse tracing environment: %v", err)
+	}
+
+	switch cfg.Mode {
+	case ModeSentry:
+	case ModeOTel:
+		if err := setupOTel(context.Background()); err != nil {
+			log.Fatalf("Failed to set up OpenTelemetry: %v", err)
+		}
+	default:
+		log.Fatalf("Unknown tracing mode: %q", cfg.Mode)
+	}
+}
+
+// OTelEnabled reports whether the instrumentation creates OpenTelemetry spans
+// instead of sentry spans.
+func OTelEnabled() bool {
+	return cfg.Mode == ModeOTel
+}
+
+// Tracer returns the OpenTelemetry tracer used by the instrumentation of
+// bricks.
+func Tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// Sets up the global tracer provider exporting spans via OTLP, sampled by the
+// default sampling policy, the global meter provider, and the W3C trace
+// context and baggage propagation. The spans and metrics are flushed on
+// shutdown, see lifecycle.PhaseFlush.
+func setupOTel(ctx context.Context) error {
+	var (
+		exporter sdktrace.SpanExporter
+		err      error
+	)
+	switch cfg.Protocol {
+	case "grpc":
+		exporter, err = otlptracegrpc.New(ctx)
+	case "http/protobuf":
+		exporter, err = otlptracehttp.New(ctx)
+	default:
+		err = fmt.Errorf("unsupported protocol %q", cfg.Protocol)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create exporter: %w", err)
+	}
+
+	res, err := newResource()
+	if err != nil {
+		return err
+	}
+
+	policy := sampling.Default()
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(policy.OTelSampler()),
+		sdktrace.WithSpanProcessor(policy.SpanProcessor(sdktrace.NewBatchSpanProcessor(exporter))),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+	lifecycle.OnShutdown(lifecycle.PhaseFlush, "otel", provider.Shutdown)
+
+	return setupOTelMetrics(ctx, res)
+}
+
+// Sets up the global meter provider exporting the metrics via OTLP, if
+// enabled by OTEL_METRICS_EXPORTER. Otherwise the metrics of the
+// instrumentation are dropped, bricks exposes its metrics using prometheus.
+func setupOTelMetrics(ctx context.Context, res *resource.Resource) error {
+	switch cfg.MetricsExporter {
+	case "none":
+		return nil
+	case "otlp":
+	default:
+		return fmt.Errorf("unsupported metrics exporter %q", cfg.MetricsExporter)
+	}
+	if cfg.MetricsProtocol != "grpc" {
+		return fmt.Errorf("unsupported metrics protocol %q", cfg.MetricsProtocol)
+	}
+
+	exporter, err := otlpmetricgrpc.New(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create metrics exporter: %w", err)
+	}
+
+	provider := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter)),
+		sdkmetric.WithResource(res),
+	)
+	otel.SetMeterProvider(provider)
+	lifecycle.OnShutdown(lifecycle.PhaseFlush, "otel metrics", provider.Shutdown)
+	return nil
+}
+
+func newResource() (*resource.Resource, error) {
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		attribute.String("deployment.environment", os.Getenv("ENVIRONMENT")),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create resource: %w", err)
+	}
+	return res, nil
+}
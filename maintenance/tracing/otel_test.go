@@ -0,0 +1,92 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package tracing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/pace/bricks/maintenance/log"
+	"github.com/pace/bricks/maintenance/tracing/sampling"
+)
+
+func setupTestOTel(t *testing.T) *tracetest.SpanRecorder {
+	mode := cfg.Mode
+	cfg.Mode = ModeOTel
+	t.Cleanup(func() { cfg.Mode = mode })
+
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return recorder
+}
+
+func TestHandlerOTel(t *testing.T) {
+	recorder := setupTestOTel(t)
+
+	var traceID string
+	h := Handler()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceID = log.TraceIDFromContext(r.Context())
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", traceID)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	require.Equal(t, trace.SpanKindServer, spans[0].SpanKind())
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext().TraceID().String())
+	require.Equal(t, "00f067aa0ba902b7", spans[0].Parent().SpanID().String())
+	require.Equal(t, codes.Error, spans[0].Status().Code)
+}
+
+func TestHandlerOTelNewTrace(t *testing.T) {
+	recorder := setupTestOTel(t)
+
+	var traceID string
+	h := Handler()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceID = log.TraceIDFromContext(r.Context())
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/test", nil))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	require.False(t, spans[0].Parent().IsValid())
+	require.Equal(t, spans[0].SpanContext().TraceID().String(), traceID)
+	require.Equal(t, codes.Unset, spans[0].Status().Code)
+}
+
+func TestHandlerOTelForced(t *testing.T) {
+	setupTestOTel(t)
+	policy := sampling.NewPolicy(0, sampling.WithForceSecret("secret"))
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSampler(policy.OTelSampler())))
+	defaultPolicy := sampling.Default()
+	sampling.SetDefault(policy)
+	t.Cleanup(func() { sampling.SetDefault(defaultPolicy) })
+
+	var sampled bool
+	h := Handler()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sampled = trace.SpanContextFromContext(r.Context()).IsSampled()
+	}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/test", nil))
+	require.False(t, sampled)
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set(sampling.HeaderForce, "secret")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	require.True(t, sampled)
+}
@@ -0,0 +1,168 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package sampling
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Maximum number of spans of a deferred trace that are buffered until its
+// root span finished, further spans are dropped.
+const maxDeferredSpans = 1000
+
+// OTelSampler returns the OpenTelemetry sampler of the policy. Traces are
+// sampled if they are forced or their remote parent was sampled, otherwise
+// the policy decides for new traces. Deferred traces are recorded, but not
+// sampled, they need the SpanProcessor of the policy.
+func (p *Policy) OTelSampler() sdktrace.Sampler {
+	return otelSampler{policy: p}
+}
+
+type otelSampler struct {
+	policy *Policy
+}
+
+func (s otelSampler) ShouldSample(params sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	parent := trace.SpanContextFromContext(params.ParentContext)
+	result := sdktrace.SamplingResult{Tracestate: parent.TraceState()}
+
+	switch {
+	case s.policy.Forced(params.ParentContext) || parent.IsSampled():
+		result.Decision = sdktrace.RecordAndSample
+	case parent.IsValid():
+		// children of deferred spans are deferred as well
+		if !parent.IsRemote() && trace.SpanFromContext(params.ParentContext).IsRecording() {
+			result.Decision = sdktrace.RecordOnly
+		}
+	default:
+		switch s.policy.decide(params.ParentContext, otelName(params)) {
+		case sample:
+			result.Decision = sdktrace.RecordAndSample
+		case deferred:
+			result.Decision = sdktrace.RecordOnly
+		}
+	}
+
+	return result
+}
+
+func (s otelSampler) Description() string {
+	return "BricksSampler"
+}
+
+// otelName returns the name of the span, gRPC span names lack the leading
+// slash of the full method.
+func otelName(params sdktrace.SamplingParameters) string {
+	for _, attr := range params.Attributes {
+		if attr.Key == "rpc.system" && attr.Value.AsString() == "grpc" && !strings.HasPrefix(params.Name, "/") {
+			return "/" + params.Name
+		}
+	}
+	return params.Name
+}
+
+// SpanProcessor returns a span processor that passes sampled spans to next
+// and buffers the spans of deferred traces until their local root span
+// finished. The buffered spans are passed on as sampled, if the root span
+// failed or was slow.
+func (p *Policy) SpanProcessor(next sdktrace.SpanProcessor) sdktrace.SpanProcessor {
+	return &tailProcessor{
+		SpanProcessor: next,
+		policy:        p,
+		traces:        make(map[trace.TraceID][]sdktrace.ReadOnlySpan),
+	}
+}
+
+type tailProcessor struct {
+	sdktrace.SpanProcessor
+	policy *Policy
+
+	mx     sync.Mutex
+	traces map[trace.TraceID][]sdktrace.ReadOnlySpan
+}
+
+func (p *tailProcessor) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	p.SpanProcessor.OnStart(parent, s)
+
+	if s.SpanContext().IsSampled() || !localRoot(s) {
+		return
+	}
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	if _, ok := p.traces[s.SpanContext().TraceID()]; !ok {
+		p.traces[s.SpanContext().TraceID()] = nil
+	}
+}
+
+func (p *tailProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	if s.SpanContext().IsSampled() {
+		p.SpanProcessor.OnEnd(s)
+		return
+	}
+
+	traceID := s.SpanContext().TraceID()
+	p.mx.Lock()
+	spans, ok := p.traces[traceID]
+	if !ok {
+		p.mx.Unlock()
+		return
+	}
+	if len(spans) < maxDeferredSpans {
+		spans = append(spans, s)
+	}
+	if !localRoot(s) {
+		p.traces[traceID] = spans
+		p.mx.Unlock()
+		return
+	}
+	delete(p.traces, traceID)
+	p.mx.Unlock()
+
+	if !p.policy.keep(failed(s), s.EndTime().Sub(s.StartTime())) {
+		return
+	}
+	for _, span := range spans {
+		sc := span.SpanContext()
+		p.SpanProcessor.OnEnd(sampledSpan{
+			ReadOnlySpan: span,
+			spanContext:  sc.WithTraceFlags(sc.TraceFlags().WithSampled(true)),
+		})
+	}
+}
+
+// localRoot reports whether the span is the first span of the trace in this
+// process
+func localRoot(s sdktrace.ReadOnlySpan) bool {
+	return !s.Parent().IsValid() || s.Parent().IsRemote()
+}
+
+// failed reports whether the span failed with a server error, client errors
+// of HTTP client spans do not count
+func failed(s sdktrace.ReadOnlySpan) bool {
+	if s.Status().Code != codes.Error {
+		return false
+	}
+	for _, attr := range s.Attributes() {
+		if attr.Key == attribute.Key("http.response.status_code") {
+			return attr.Value.AsInt64() >= 500
+		}
+	}
+	return true
+}
+
+// sampledSpan is a deferred span that was kept
+type sampledSpan struct {
+	sdktrace.ReadOnlySpan
+	spanContext trace.SpanContext
+}
+
+func (s sampledSpan) SpanContext() trace.SpanContext {
+	return s.spanContext
+}
@@ -0,0 +1,93 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package sampling
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newTestTracer(p *Policy) (trace.Tracer, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(p.OTelSampler()),
+		sdktrace.WithSpanProcessor(p.SpanProcessor(recorder)),
+	)
+	return provider.Tracer("test"), recorder
+}
+
+func TestOTelSampler(t *testing.T) {
+	tracer, recorder := newTestTracer(NewPolicy(0, WithRule("sampled", 1)))
+
+	ctx, root := tracer.Start(context.Background(), "sampled")
+	_, child := tracer.Start(ctx, "child")
+	child.End()
+	root.End()
+	require.True(t, root.SpanContext().IsSampled())
+	require.True(t, child.SpanContext().IsSampled())
+
+	_, dropped := tracer.Start(context.Background(), "dropped")
+	dropped.End()
+	require.False(t, dropped.IsRecording())
+
+	// the decision of remote parents is inherited
+	remote := trace.ContextWithRemoteSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{1},
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	}))
+	_, inherited := tracer.Start(remote, "dropped")
+	inherited.End()
+	require.True(t, inherited.SpanContext().IsSampled())
+
+	require.Len(t, recorder.Ended(), 3)
+}
+
+func TestOTelSamplerGRPC(t *testing.T) {
+	tracer, _ := newTestTracer(NewPolicy(0, WithRule("/pace.Service/*", 1)))
+
+	_, span := tracer.Start(context.Background(), "pace.Service/Get", trace.WithAttributes(
+		attribute.String("rpc.system", "grpc"),
+	))
+	span.End()
+	require.True(t, span.SpanContext().IsSampled())
+}
+
+func TestOTelTail(t *testing.T) {
+	tracer, recorder := newTestTracer(NewPolicy(0, WithErrors(), WithSlowThreshold(time.Hour)))
+
+	// deferred traces are dropped if they succeed
+	ctx, root := tracer.Start(context.Background(), "ok")
+	_, child := tracer.Start(ctx, "child")
+	child.End()
+	root.End()
+	require.False(t, root.SpanContext().IsSampled())
+	require.Empty(t, recorder.Ended())
+
+	// and kept if they fail
+	ctx, root = tracer.Start(context.Background(), "failed")
+	_, child = tracer.Start(ctx, "child")
+	child.End()
+	root.SetStatus(codes.Error, "failed")
+	root.End()
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	for _, span := range spans {
+		require.True(t, span.SpanContext().IsSampled())
+	}
+
+	// and if they are slow
+	start := time.Now().Add(-2 * time.Hour)
+	_, root = tracer.Start(context.Background(), "slow", trace.WithTimestamp(start))
+	root.End()
+	require.Len(t, recorder.Ended(), 3)
+}
@@ -0,0 +1,280 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+// Package sampling decides which traces are recorded. The decision is made
+// when a trace starts (head) using per-name sample rates, a rate limit and
+// forced sampling for debugging. Traces that are not sampled at the start can
+// still be kept once their root span finished (tail), if the request failed
+// or was slow.
+package sampling
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/caarlos0/env/v11"
+	"google.golang.org/grpc/metadata"
+)
+
+// DefaultRate is the sample rate used if no rate is configured.
+const DefaultRate = 0.1
+
+// HeaderForce is the HTTP header and gRPC metadata key that forces the
+// sampling of a trace, if its value is the secret of the policy.
+const HeaderForce = "X-Force-Sampling"
+
+type config struct {
+	Rate          *float64      `env:"TRACING_SAMPLE_RATE"`
+	Rules         []string      `env:"TRACING_SAMPLE_RULES" envSeparator:","`
+	RateLimit     float64       `env:"TRACING_SAMPLE_RATE_LIMIT"`
+	Errors        bool          `env:"TRACING_SAMPLE_ERRORS"`
+	SlowThreshold time.Duration `env:"TRACING_SAMPLE_SLOW_THRESHOLD"`
+	ForceSecret   string        `env:"TRACING_FORCE_SAMPLING_SECRET"`
+}
+
+// decision of the sampling when a trace starts
+type decision int
+
+const (
+	drop decision = iota
+	sample
+	// deferred traces are recorded, but only kept if the tail decision
+	// says so
+	deferred
+)
+
+type rule struct {
+	pattern string
+	rate    float64
+}
+
+// Policy decides which traces are sampled. Traces are identified by the name
+// of their root span, which is "METHOD /route" for HTTP requests and the full
+// method, e.g. "/package.Service/Method", for gRPC calls.
+type Policy struct {
+	rate          float64
+	rules         []rule
+	limiter       *limiter // nil if unlimited
+	errors        bool
+	slowThreshold time.Duration
+	forceSecret   string
+}
+
+// Option configures a Policy.
+type Option func(*Policy)
+
+// WithRule samples the traces whose name matches the pattern with the rate.
+// The patterns are matched using path.Match in the order they were added, the
+// first match wins.
+func WithRule(pattern string, rate float64) Option {
+	return func(p *Policy) {
+		p.rules = append(p.rules, rule{pattern: pattern, rate: rate})
+	}
+}
+
+// WithRateLimit limits the traces that are sampled by rate to perSecond.
+// Traces that are forced, inherit the decision of their parent or are kept by
+// the tail decision are not limited.
+func WithRateLimit(perSecond float64) Option {
+	return func(p *Policy) {
+		p.limiter = newLimiter(perSecond)
+	}
+}
+
+// WithErrors keeps all traces whose root span failed, e.g. with a 5xx
+// status code.
+func WithErrors() Option {
+	return func(p *Policy) {
+		p.errors = true
+	}
+}
+
+// WithSlowThreshold keeps all traces whose root span took at least the
+// threshold.
+func WithSlowThreshold(threshold time.Duration) Option {
+	return func(p *Policy) {
+		p.slowThreshold = threshold
+	}
+}
+
+// WithForceSecret allows to force the sampling of a trace by passing the
+// secret in the HeaderForce header or metadata.
+func WithForceSecret(secret string) Option {
+	return func(p *Policy) {
+		p.forceSecret = secret
+	}
+}
+
+// NewPolicy creates a policy that samples traces with the rate, unless a
+// rule matches.
+func NewPolicy(rate float64, opts ...Option) *Policy {
+	p := &Policy{rate: rate}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// FromEnv creates a policy configured by the environment. The rate is used
+// unless TRACING_SAMPLE_RATE is set.
+func FromEnv(rate float64) (*Policy, error) {
+	var cfg config
+	if err := env.Parse(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse sampling environment: %w", err)
+	}
+
+	if cfg.Rate != nil {
+		rate = *cfg.Rate
+	}
+
+	var opts []Option
+	for _, r := range cfg.Rules {
+		i := strings.LastIndex(r, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("invalid sampling rule %q: missing rate", r)
+		}
+		pattern := strings.TrimSpace(r[:i])
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid sampling rule %q: %w", r, err)
+		}
+		ruleRate, err := strconv.ParseFloat(strings.TrimSpace(r[i+1:]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sampling rule %q: %w", r, err)
+		}
+		opts = append(opts, WithRule(pattern, ruleRate))
+	}
+	if cfg.RateLimit > 0 {
+		opts = append(opts, WithRateLimit(cfg.RateLimit))
+	}
+	if cfg.Errors {
+		opts = append(opts, WithErrors())
+	}
+	if cfg.SlowThreshold > 0 {
+		opts = append(opts, WithSlowThreshold(cfg.SlowThreshold))
+	}
+	if cfg.ForceSecret != "" {
+		opts = append(opts, WithForceSecret(cfg.ForceSecret))
+	}
+
+	return NewPolicy(rate, opts...), nil
+}
+
+var (
+	defaultMx     sync.RWMutex
+	defaultPolicy = NewPolicy(DefaultRate)
+)
+
+// Default returns the policy used by the tracing of bricks.
+func Default() *Policy {
+	defaultMx.RLock()
+	defer defaultMx.RUnlock()
+	return defaultPolicy
+}
+
+// SetDefault replaces the policy used by the tracing of bricks.
+func SetDefault(p *Policy) {
+	defaultMx.Lock()
+	defer defaultMx.Unlock()
+	defaultPolicy = p
+}
+
+type forceKey struct{}
+
+// ContextWithForce returns a context that forces the sampling of traces
+// started with it, if the value is the secret of the policy.
+func (p *Policy) ContextWithForce(ctx context.Context, value string) context.Context {
+	if p.forceSecret == "" || subtle.ConstantTimeCompare([]byte(value), []byte(p.forceSecret)) != 1 {
+		return ctx
+	}
+	return context.WithValue(ctx, forceKey{}, true)
+}
+
+// Forced reports whether traces started with the context are sampled
+// regardless of the rate, either because of ContextWithForce or the incoming
+// gRPC metadata.
+func (p *Policy) Forced(ctx context.Context) bool {
+	if forced, _ := ctx.Value(forceKey{}).(bool); forced {
+		return true
+	}
+	if p.forceSecret == "" {
+		return false
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get(HeaderForce) {
+		if subtle.ConstantTimeCompare([]byte(value), []byte(p.forceSecret)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// Rate returns the sample rate of traces with the name.
+func (p *Policy) Rate(name string) float64 {
+	for _, r := range p.rules {
+		if ok, _ := path.Match(r.pattern, name); ok {
+			return r.rate
+		}
+	}
+	return p.rate
+}
+
+// decide whether a new trace with the name is sampled
+func (p *Policy) decide(ctx context.Context, name string) decision {
+	if p.Forced(ctx) {
+		return sample
+	}
+	if rate := p.Rate(name); rate > 0 && rand.Float64() < rate && p.limiter.allow() {
+		return sample
+	}
+	if p.tail() {
+		return deferred
+	}
+	return drop
+}
+
+// tail reports whether deferred traces need to be recorded
+func (p *Policy) tail() bool {
+	return p.errors || p.slowThreshold > 0
+}
+
+// keep reports whether a deferred trace is kept once its root span finished
+func (p *Policy) keep(failed bool, duration time.Duration) bool {
+	return (p.errors && failed) || (p.slowThreshold > 0 && duration >= p.slowThreshold)
+}
+
+// limiter is a token bucket, that allows up to one second of traces at once.
+// A nil limiter allows all traces.
+type limiter struct {
+	mx        sync.Mutex
+	perSecond float64
+	tokens    float64
+	last      time.Time
+}
+
+func newLimiter(perSecond float64) *limiter {
+	return &limiter{perSecond: perSecond, tokens: math.Max(perSecond, 1), last: time.Now()}
+}
+
+func (l *limiter) allow() bool {
+	if l == nil {
+		return true
+	}
+	l.mx.Lock()
+	defer l.mx.Unlock()
+
+	now := time.Now()
+	l.tokens = math.Min(l.tokens+now.Sub(l.last).Seconds()*l.perSecond, math.Max(l.perSecond, 1))
+	l.last = now
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
@@ -0,0 +1,96 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package sampling
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestPolicyRate(t *testing.T) {
+	p := NewPolicy(0.1,
+		WithRule("GET /beta/orders/*", 0.5),
+		WithRule("/pace.Service/*", 1),
+		WithRule("GET /beta/*", 0),
+	)
+	require.Equal(t, 0.5, p.Rate("GET /beta/orders/{id}"))
+	require.Equal(t, 0.0, p.Rate("GET /beta/orders"))
+	require.Equal(t, 1.0, p.Rate("/pace.Service/Get"))
+	require.Equal(t, 0.1, p.Rate("POST /beta/orders"))
+}
+
+func TestPolicyDecide(t *testing.T) {
+	ctx := context.Background()
+
+	p := NewPolicy(0, WithRule("always", 1))
+	require.Equal(t, sample, p.decide(ctx, "always"))
+	require.Equal(t, drop, p.decide(ctx, "never"))
+
+	p = NewPolicy(0, WithErrors())
+	require.Equal(t, deferred, p.decide(ctx, "never"))
+
+	p = NewPolicy(1, WithRateLimit(2))
+	require.Equal(t, sample, p.decide(ctx, "a"))
+	require.Equal(t, sample, p.decide(ctx, "a"))
+	require.Equal(t, drop, p.decide(ctx, "a"))
+}
+
+func TestPolicyKeep(t *testing.T) {
+	require.False(t, NewPolicy(0).keep(true, time.Hour))
+
+	p := NewPolicy(0, WithErrors())
+	require.True(t, p.keep(true, 0))
+	require.False(t, p.keep(false, time.Hour))
+
+	p = NewPolicy(0, WithSlowThreshold(time.Second))
+	require.False(t, p.keep(true, 0))
+	require.True(t, p.keep(false, time.Second))
+}
+
+func TestPolicyForced(t *testing.T) {
+	ctx := context.Background()
+
+	p := NewPolicy(0)
+	require.False(t, p.Forced(p.ContextWithForce(ctx, "")))
+
+	p = NewPolicy(0, WithForceSecret("secret"))
+	require.False(t, p.Forced(p.ContextWithForce(ctx, "wrong")))
+	require.True(t, p.Forced(p.ContextWithForce(ctx, "secret")))
+	require.Equal(t, sample, p.decide(p.ContextWithForce(ctx, "secret"), "a"))
+
+	md := metadata.Pairs(HeaderForce, "secret")
+	require.True(t, p.Forced(metadata.NewIncomingContext(ctx, md)))
+	md = metadata.Pairs(HeaderForce, "wrong")
+	require.False(t, p.Forced(metadata.NewIncomingContext(ctx, md)))
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("TRACING_SAMPLE_RULES", "GET /beta/*=0.5, /pace.Service/Get=1")
+	t.Setenv("TRACING_SAMPLE_SLOW_THRESHOLD", "2s")
+	t.Setenv("TRACING_FORCE_SAMPLING_SECRET", "secret")
+
+	p, err := FromEnv(0.2)
+	require.NoError(t, err)
+	require.Equal(t, 0.5, p.Rate("GET /beta/orders"))
+	require.Equal(t, 1.0, p.Rate("/pace.Service/Get"))
+	require.Equal(t, 0.2, p.Rate("GET /"))
+	require.Equal(t, 2*time.Second, p.slowThreshold)
+	require.Equal(t, "secret", p.forceSecret)
+
+	t.Setenv("TRACING_SAMPLE_RATE", "0.3")
+	p, err = FromEnv(0.2)
+	require.NoError(t, err)
+	require.Equal(t, 0.3, p.Rate("GET /"))
+
+	t.Setenv("TRACING_SAMPLE_RULES", "GET /beta/*")
+	_, err = FromEnv(0.2)
+	require.Error(t, err)
+
+	t.Setenv("TRACING_SAMPLE_RULES", "[=1")
+	_, err = FromEnv(0.2)
+	require.Error(t, err)
+}
@@ -0,0 +1,100 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package sampling
+
+import (
+	"strings"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// Data key marking transactions whose sampling is deferred until they finish
+const deferredKey = "sampling.deferred"
+
+// SentrySampler returns the sentry sampler of the policy. It is only used for
+// transactions without an explicit decision, e.g. of the parent. Deferred
+// transactions are recorded and need to be dropped by
+// KeepSentryTransaction.
+func (p *Policy) SentrySampler() sentry.TracesSampler {
+	return func(ctx sentry.SamplingContext) float64 {
+		span := ctx.Span
+		name := span.Name
+		if name == "" {
+			name = span.Description
+		}
+
+		switch p.decide(span.Context(), name) {
+		case sample:
+			return 1
+		case deferred:
+			span.SetData(deferredKey, true)
+			return 1
+		default:
+			return 0
+		}
+	}
+}
+
+// SentryTraceHeader returns the sentry-trace header to propagate the span.
+// Use it instead of span.ToSentryTrace, it leaves out the sampled flag of
+// deferred transactions, as they may still be dropped. Downstream services
+// make their own decision then.
+func SentryTraceHeader(span *sentry.Span) string {
+	trace := span.ToSentryTrace()
+	if deferredSpan(span) {
+		trace = strings.TrimSuffix(trace, "-1")
+	}
+	return trace
+}
+
+// SentryBaggageHeader returns the baggage header to propagate the span.
+// Use it instead of span.ToBaggage, it leaves out the sampling decision and
+// rate of deferred transactions.
+func SentryBaggageHeader(span *sentry.Span) string {
+	baggage := span.ToBaggage()
+	if baggage == "" || !deferredSpan(span) {
+		return baggage
+	}
+	dsc, err := sentry.DynamicSamplingContextFromHeader([]byte(baggage))
+	if err != nil {
+		return ""
+	}
+	delete(dsc.Entries, "sampled")
+	delete(dsc.Entries, "sample_rate")
+	return dsc.String()
+}
+
+func deferredSpan(span *sentry.Span) bool {
+	t := span.GetTransaction()
+	if t == nil {
+		return false
+	}
+	deferred, _ := t.Data[deferredKey].(bool)
+	return deferred
+}
+
+// KeepSentryTransaction makes the tail decision for the transaction, it is
+// meant to be used in sentry.ClientOptions.BeforeSendTransaction. Deferred
+// transactions are kept if they failed with a server error or were slow.
+func (p *Policy) KeepSentryTransaction(event *sentry.Event) bool {
+	if _, ok := event.Extra[deferredKey]; !ok {
+		return true
+	}
+	delete(event.Extra, deferredKey)
+
+	status, _ := event.Contexts["trace"]["status"].(sentry.SpanStatus)
+	return p.keep(serverError(status), event.Timestamp.Sub(event.StartTime))
+}
+
+func serverError(status sentry.SpanStatus) bool {
+	switch status {
+	case sentry.SpanStatusUnknown,
+		sentry.SpanStatusDeadlineExceeded,
+		sentry.SpanStatusUnimplemented,
+		sentry.SpanStatusInternalError,
+		sentry.SpanStatusUnavailable,
+		sentry.SpanStatusDataLoss:
+		return true
+	}
+	return false
+}
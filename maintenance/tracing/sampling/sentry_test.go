@@ -0,0 +1,79 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package sampling
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/stretchr/testify/require"
+)
+
+func startTransaction(t *testing.T, p *Policy, name string) *sentry.Span {
+	client, err := sentry.NewClient(sentry.ClientOptions{
+		EnableTracing: true,
+		TracesSampler: p.SentrySampler(),
+	})
+	require.NoError(t, err)
+	ctx := sentry.SetHubOnContext(context.Background(), sentry.NewHub(client, sentry.NewScope()))
+	return sentry.StartTransaction(ctx, name)
+}
+
+func TestSentrySampler(t *testing.T) {
+	p := NewPolicy(0, WithRule("sampled", 1))
+	require.Equal(t, sentry.SampledTrue, startTransaction(t, p, "sampled").Sampled)
+	require.Equal(t, sentry.SampledFalse, startTransaction(t, p, "dropped").Sampled)
+
+	p = NewPolicy(0, WithErrors())
+	span := startTransaction(t, p, "deferred")
+	require.Equal(t, sentry.SampledTrue, span.Sampled)
+	require.Equal(t, true, span.Data[deferredKey])
+}
+
+func TestSentryHeaders(t *testing.T) {
+	p := NewPolicy(0, WithRule("sampled", 1), WithErrors())
+
+	span := startTransaction(t, p, "sampled")
+	require.True(t, strings.HasSuffix(SentryTraceHeader(span), "-1"))
+	require.Contains(t, SentryBaggageHeader(span), "sentry-sampled=true")
+
+	span = startTransaction(t, p, "deferred")
+	child := span.StartChild("child")
+	for _, s := range []*sentry.Span{span, child} {
+		trace := SentryTraceHeader(s)
+		require.Equal(t, s.TraceID.String()+"-"+s.SpanID.String(), trace)
+		require.NotContains(t, SentryBaggageHeader(s), "sentry-sampled")
+		require.NotContains(t, SentryBaggageHeader(s), "sentry-sample_rate")
+	}
+}
+
+func TestKeepSentryTransaction(t *testing.T) {
+	p := NewPolicy(0, WithErrors(), WithSlowThreshold(time.Second))
+	start := time.Now()
+
+	event := func(status sentry.SpanStatus, duration time.Duration, deferred bool) *sentry.Event {
+		e := &sentry.Event{
+			Contexts:  map[string]sentry.Context{"trace": {"status": status}},
+			Extra:     map[string]interface{}{},
+			StartTime: start,
+			Timestamp: start.Add(duration),
+		}
+		if deferred {
+			e.Extra[deferredKey] = true
+		}
+		return e
+	}
+
+	require.True(t, p.KeepSentryTransaction(event(sentry.SpanStatusOK, 0, false)))
+	require.False(t, p.KeepSentryTransaction(event(sentry.SpanStatusOK, 0, true)))
+	require.False(t, p.KeepSentryTransaction(event(sentry.SpanStatusNotFound, 0, true)))
+	require.True(t, p.KeepSentryTransaction(event(sentry.SpanStatusInternalError, 0, true)))
+	require.True(t, p.KeepSentryTransaction(event(sentry.SpanStatusOK, time.Second, true)))
+
+	e := event(sentry.SpanStatusUnavailable, 0, true)
+	require.True(t, p.KeepSentryTransaction(e))
+	require.NotContains(t, e.Extra, deferredKey)
+}
@@ -9,8 +9,14 @@ import (
 	"github.com/getsentry/sentry-go"
 	"github.com/pace/bricks/maintenance/util"
 	"github.com/zenazn/goji/web/mutil"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 
 	_ "github.com/pace/bricks/internal/sentry"
+	"github.com/pace/bricks/maintenance/tracing/sampling"
 )
 
 type traceHandler struct {
@@ -19,7 +25,12 @@ type traceHandler struct {
 
 // Trace the service function handler execution
 func (h *traceHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
+	if OTelEnabled() {
+		h.serveOTel(w, r)
+		return
+	}
+
+	ctx := sampling.Default().ContextWithForce(r.Context(), r.Header.Get(sampling.HeaderForce))
 	hub := sentry.CurrentHub()
 
 	options := []sentry.SpanOption{
@@ -28,6 +39,10 @@ func (h *traceHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		sentry.WithTransactionSource(sentry.SourceURL),
 		sentry.WithSpanOrigin(sentry.SpanOriginStdLib),
 	}
+	// overrides the decision of the parent
+	if sampling.Default().Forced(ctx) {
+		options = append(options, sentry.WithSpanSampled(sentry.SampledTrue))
+	}
 
 	transaction := sentry.StartTransaction(ctx,
 		getHTTPSpanName(r),
@@ -55,6 +70,37 @@ func (h *traceHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	h.next.ServeHTTP(ww, r)
 }
 
+// Trace the service function handler execution with an OpenTelemetry span,
+// continuing the trace of the W3C trace context headers
+func (h *traceHandler) serveOTel(w http.ResponseWriter, r *http.Request) {
+	ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	ctx = sampling.Default().ContextWithForce(ctx, r.Header.Get(sampling.HeaderForce))
+	ctx, span := Tracer().Start(ctx, getHTTPSpanName(r),
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("http.request.method", r.Method),
+			attribute.String("url.path", r.URL.Path),
+		),
+	)
+
+	ww := mutil.WrapWriter(w)
+
+	defer func() {
+		status := ww.Status()
+		span.SetAttributes(
+			attribute.Int("http.response.status_code", status),
+			attribute.Int("http.response.body.size", ww.BytesWritten()),
+		)
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+
+		span.End()
+	}()
+
+	h.next.ServeHTTP(ww, r.WithContext(ctx))
+}
+
 // Handler generates a tracing handler that decodes the current trace from the wire.
 // The tracing handler will not start traces for the list of ignoredPrefixes.
 func Handler(ignoredPrefixes ...string) func(http.Handler) http.Handler {
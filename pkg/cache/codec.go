@@ -0,0 +1,87 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+)
+
+// Codec encodes and decodes the values of a Typed cache.
+type Codec interface {
+	// Marshal returns the encoding of v.
+	Marshal(v any) ([]byte, error)
+	// Unmarshal decodes the data into the value v points to.
+	Unmarshal(data []byte, v any) error
+}
+
+// Codecs for the Typed cache.
+var (
+	// JSONCodec uses encoding/json.
+	JSONCodec Codec = jsonCodec{}
+	// GobCodec uses encoding/gob.
+	GobCodec Codec = gobCodec{}
+	// MsgpackCodec uses MessagePack.
+	MsgpackCodec Codec = msgpackCodec{}
+	// ProtobufCodec uses protocol buffers. The values must be pointers to
+	// proto.Message implementations.
+	ProtobufCodec Codec = protobufCodec{}
+)
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+type gobCodec struct{}
+
+func (gobCodec) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) Marshal(v any) ([]byte, error)      { return msgpack.Marshal(v) }
+func (msgpackCodec) Unmarshal(data []byte, v any) error { return msgpack.Unmarshal(data, v) }
+
+type protobufCodec struct{}
+
+func (protobufCodec) Marshal(v any) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("protobuf: %T is no proto.Message", v)
+	}
+	return proto.Marshal(m)
+}
+
+func (protobufCodec) Unmarshal(data []byte, v any) error {
+	m, ok := v.(proto.Message)
+	if !ok {
+		// v points to a nil pointer to a message, as used by the Typed cache
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Pointer {
+			return fmt.Errorf("protobuf: %T is no proto.Message", v)
+		}
+		if rv.Elem().IsNil() {
+			rv.Elem().Set(reflect.New(rv.Elem().Type().Elem()))
+		}
+		if m, ok = rv.Elem().Interface().(proto.Message); !ok {
+			return fmt.Errorf("protobuf: %T is no proto.Message", v)
+		}
+	}
+	return proto.Unmarshal(data, m)
+}
@@ -12,4 +12,7 @@ var (
 	// The caching backend produced an error that is not reflected by any other
 	// error.
 	ErrBackend = errors.New("cache backend error")
+
+	// The value under the given key could not be decrypted or decoded.
+	ErrInvalidValue = errors.New("invalid value")
 )
@@ -0,0 +1,217 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	pberrors "github.com/pace/bricks/maintenance/errors"
+	"github.com/pace/bricks/maintenance/log"
+	redislock "github.com/pace/bricks/pkg/lock/redis"
+)
+
+// Interval in which the cache is checked while another process loads the
+// value, see WithLock.
+const loadingPollInterval = 50 * time.Millisecond
+
+// Loader returns the value of a key that is not in the cache.
+type Loader func(ctx context.Context) ([]byte, error)
+
+// Loading wraps a cache to load missing values, so that callers don't need
+// to implement the usual get, load and put on their own. Loading a key is
+// deduplicated within the process, and optionally across processes, so that
+// an expiring popular key doesn't cause a thundering herd. It is safe for
+// concurrent use.
+//
+// To serve stale values, values are stored with a ttl that is extended by the
+// longer of the stale windows. Get of the wrapped cache returns the extended
+// ttl.
+type Loading struct {
+	cache Cache
+	group singleflight.Group
+
+	staleWhileRevalidate time.Duration
+	staleIfError         time.Duration
+	lockPrefix           string
+	lockTTL              time.Duration
+}
+
+// LoadingOption configures a Loading cache.
+type LoadingOption func(*Loading)
+
+// WithStaleWhileRevalidate returns values for the duration after they
+// expired, while they are loaded again in the background.
+func WithStaleWhileRevalidate(d time.Duration) LoadingOption {
+	return func(c *Loading) {
+		c.staleWhileRevalidate = d
+	}
+}
+
+// WithStaleIfError returns values for the duration after they expired if
+// loading them again fails.
+func WithStaleIfError(d time.Duration) LoadingOption {
+	return func(c *Loading) {
+		c.staleIfError = d
+	}
+}
+
+// WithLock loads a value only in one process at a time, using a lock in the
+// default redis database, see pkg/lock/redis. The name of the lock is the
+// prefix followed by the key. Other processes wait for the value to be put
+// into the cache, but at most for the ttl of the lock, then they load the
+// value on their own.
+func WithLock(prefix string, ttl time.Duration) LoadingOption {
+	return func(c *Loading) {
+		c.lockPrefix = prefix
+		c.lockTTL = ttl
+	}
+}
+
+// NewLoading returns a new cache that loads missing values into c.
+func NewLoading(c Cache, opts ...LoadingOption) *Loading {
+	l := &Loading{cache: c}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// GetOrLoad returns the value stored under the key. If there is no value
+// stored, it is loaded and stored with the ttl. If ttl is zero, the value is
+// never automatically forgotten. Callers loading the same key at the same time
+// share the result of a single call of the loader.
+func (c *Loading) GetOrLoad(ctx context.Context, key string, ttl time.Duration, loader Loader) ([]byte, error) {
+	value, remaining, err := c.cache.Get(ctx, key)
+	if errors.Is(err, ErrNotFound) {
+		return c.load(ctx, key, ttl, loader)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// values without ttl and values within their ttl are fresh
+	window := c.staleWindow()
+	if remaining == 0 || remaining > window {
+		return value, nil
+	}
+	expiredFor := window - remaining
+	if expiredFor <= c.staleWhileRevalidate {
+		c.revalidate(ctx, key, ttl, loader)
+		return value, nil
+	}
+	loaded, err := c.load(ctx, key, ttl, loader)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Str("key", key).Msg("cache: could not load value, using stale value")
+		return value, nil
+	}
+	return loaded, nil
+}
+
+func (c *Loading) staleWindow() time.Duration {
+	return max(c.staleWhileRevalidate, c.staleIfError)
+}
+
+// Loads the value in the background, ignoring the cancellation of the
+// context.
+func (c *Loading) revalidate(ctx context.Context, key string, ttl time.Duration, loader Loader) {
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		defer pberrors.HandleWithCtx(ctx, fmt.Sprintf("revalidate cache key %q", key)) // handle panics
+		if _, err := c.load(ctx, key, ttl, loader); err != nil {
+			log.Ctx(ctx).Debug().Err(err).Str("key", key).Msg("cache: could not revalidate value")
+		}
+	}()
+}
+
+// Loads the value and puts it into the cache. Concurrent calls for the same
+// key share the result of the first call. The value is loaded with a context
+// that is not cancelled with the context of the first caller, each caller
+// stops waiting for the value once its own context is done.
+func (c *Loading) load(ctx context.Context, key string, ttl time.Duration, loader Loader) ([]byte, error) {
+	loadCtx := context.WithoutCancel(ctx)
+	ch := c.group.DoChan(key, func() (v interface{}, err error) {
+		// DoChan doesn't propagate panics to the callers
+		defer func() {
+			if rp := recover(); rp != nil {
+				err = fmt.Errorf("cache: loading key %q panicked: %v", key, rp)
+				pberrors.Handle(loadCtx, err)
+			}
+		}()
+		return c.loadShared(loadCtx, key, ttl, loader)
+	})
+
+	var res singleflight.Result
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res = <-ch:
+	}
+	if res.Err != nil {
+		return nil, res.Err
+	}
+	// callers must not share the value
+	value := make([]byte, len(res.Val.([]byte)))
+	copy(value, res.Val.([]byte))
+	return value, nil
+}
+
+// Loads the value once for all callers of load, see load.
+func (c *Loading) loadShared(ctx context.Context, key string, ttl time.Duration, loader Loader) ([]byte, error) {
+	if c.lockTTL > 0 {
+		lock := redislock.NewLock(c.lockPrefix+key, redislock.SetTTL(c.lockTTL))
+		ok, err := lock.Acquire(ctx)
+		switch {
+		case err != nil:
+			// loading in several processes is better than not at all
+			log.Ctx(ctx).Debug().Err(err).Str("key", key).Msg("cache: could not acquire lock, loading anyway")
+		case ok:
+			defer lock.Release(ctx) // nolint: errcheck
+		default:
+			if value, ok := c.awaitLoaded(ctx, key); ok {
+				return value, nil
+			}
+		}
+	}
+
+	value, err := loader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		value = []byte{}
+	}
+	storedTTL := ttl
+	if ttl != 0 {
+		storedTTL += c.staleWindow()
+	}
+	if err := c.cache.Put(ctx, key, value, storedTTL); err != nil {
+		// the value can be used nevertheless
+		log.Ctx(ctx).Debug().Err(err).Str("key", key).Msg("cache: could not put loaded value")
+		pberrors.Handle(ctx, err)
+	}
+	return value, nil
+}
+
+// Waits for another process to put a fresh value into the cache. Returns
+// false if there is none within the ttl of the lock.
+func (c *Loading) awaitLoaded(ctx context.Context, key string) ([]byte, bool) {
+	timeout := time.After(c.lockTTL)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, false
+		case <-timeout:
+			return nil, false
+		case <-time.After(loadingPollInterval):
+		}
+		value, remaining, err := c.cache.Get(ctx, key)
+		if err == nil && (remaining == 0 || remaining > c.staleWindow()) {
+			return value, true
+		}
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pace/bricks/maintenance/log"
+	"github.com/pace/bricks/pkg/cache"
+)
+
+func TestLoading_GetOrLoad(t *testing.T) {
+	ctx := log.WithContext(context.Background())
+	c := cache.NewLoading(cache.InMemory())
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	loader := func(context.Context) ([]byte, error) {
+		calls.Add(1)
+		<-release
+		return []byte("bar"), nil
+	}
+
+	// concurrent callers share a single load
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := c.GetOrLoad(ctx, "foo", time.Hour, loader)
+			require.NoError(t, err)
+			require.Equal(t, []byte("bar"), value)
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	require.Equal(t, int32(1), calls.Load())
+
+	// the value is cached
+	value, err := c.GetOrLoad(ctx, "foo", time.Hour, loader)
+	require.NoError(t, err)
+	require.Equal(t, []byte("bar"), value)
+	require.Equal(t, int32(1), calls.Load())
+
+	// errors are not cached
+	_, err = c.GetOrLoad(ctx, "baz", time.Hour, func(context.Context) ([]byte, error) {
+		return nil, errors.New("failed")
+	})
+	require.Error(t, err)
+}
+
+func TestLoading_cancel(t *testing.T) {
+	ctx := log.WithContext(context.Background())
+	c := cache.NewLoading(cache.InMemory())
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	loader := func(ctx context.Context) ([]byte, error) {
+		close(started)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-release:
+		}
+		return []byte("bar"), nil
+	}
+
+	// the first caller gives up while loading
+	firstCtx, cancel := context.WithCancel(ctx)
+	first := make(chan error)
+	go func() {
+		_, err := c.GetOrLoad(firstCtx, "foo", time.Hour, loader)
+		first <- err
+	}()
+	<-started
+
+	second := make(chan []byte)
+	go func() {
+		value, err := c.GetOrLoad(ctx, "foo", time.Hour, loader)
+		require.NoError(t, err)
+		second <- value
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	require.ErrorIs(t, <-first, context.Canceled)
+
+	// the second caller still gets the value
+	close(release)
+	require.Equal(t, []byte("bar"), <-second)
+}
+
+func TestLoading_staleWhileRevalidate(t *testing.T) {
+	ctx := log.WithContext(context.Background())
+	c := cache.NewLoading(cache.InMemory(), cache.WithStaleWhileRevalidate(time.Hour))
+
+	_, err := c.GetOrLoad(ctx, "foo", time.Millisecond, func(context.Context) ([]byte, error) {
+		return []byte("old"), nil
+	})
+	require.NoError(t, err)
+	time.Sleep(2 * time.Millisecond)
+
+	loaded := make(chan struct{})
+	value, err := c.GetOrLoad(ctx, "foo", time.Hour, func(context.Context) ([]byte, error) {
+		defer close(loaded)
+		return []byte("new"), nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []byte("old"), value)
+
+	<-loaded
+	require.Eventually(t, func() bool {
+		value, err := c.GetOrLoad(ctx, "foo", time.Hour, func(context.Context) ([]byte, error) {
+			return nil, errors.New("unexpected load")
+		})
+		return err == nil && string(value) == "new"
+	}, time.Second, time.Millisecond)
+}
+
+func TestLoading_staleIfError(t *testing.T) {
+	ctx := log.WithContext(context.Background())
+	c := cache.NewLoading(cache.InMemory(), cache.WithStaleIfError(time.Hour))
+
+	_, err := c.GetOrLoad(ctx, "foo", time.Millisecond, func(context.Context) ([]byte, error) {
+		return []byte("old"), nil
+	})
+	require.NoError(t, err)
+	time.Sleep(2 * time.Millisecond)
+
+	value, err := c.GetOrLoad(ctx, "foo", time.Hour, func(context.Context) ([]byte, error) {
+		return nil, errors.New("failed")
+	})
+	require.NoError(t, err)
+	require.Equal(t, []byte("old"), value)
+
+	// expired values are loaded synchronously
+	value, err = c.GetOrLoad(ctx, "foo", time.Hour, func(context.Context) ([]byte, error) {
+		return []byte("new"), nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []byte("new"), value)
+}
@@ -3,45 +3,193 @@
 package cache
 
 import (
+	"container/heap"
+	"container/list"
 	"context"
 	"fmt"
+	"runtime"
 	"sync"
 	"time"
 )
 
 var _ Cache = (*Memory)(nil)
 
+// EvictionPolicy decides which value a bounded Memory cache evicts first.
+type EvictionPolicy int
+
+const (
+	// EvictLRU evicts the least recently used value first.
+	EvictLRU EvictionPolicy = iota
+	// EvictLFU evicts the least frequently used value first. Of values used
+	// equally often, the least recently used is evicted first.
+	EvictLFU
+)
+
 // Memory is the cache that stores everything in memory.  It is safe for
 // concurrent use.
 type Memory struct {
-	values map[string]inMemoryValue
+	*memory // separate, so that the janitor doesn't keep the cache alive
+}
+
+type memory struct {
+	name       string
+	tier       string
+	maxEntries int
+	maxBytes   int
+	eviction   evictionPolicy // nil if the cache is unbounded
+
 	mx     sync.RWMutex
+	values map[string]*inMemoryValue
+	bytes  int
+	clock  uint64 // incremented on every use of a value
 }
 
 type inMemoryValue struct {
-	value     []byte
+	key       string
+	value     []byte // never changed once stored
 	expiresAt time.Time
+
+	// state of the eviction policy
+	elem     *list.Element
+	index    int
+	uses     uint64
+	lastUsed uint64
+}
+
+func (v *inMemoryValue) size() int {
+	return len(v.key) + len(v.value)
+}
+
+// MemoryOption configures a Memory cache.
+type MemoryOption func(*memoryOptions)
+
+type memoryOptions struct {
+	name            string
+	tier            string
+	maxEntries      int
+	maxBytes        int
+	policy          EvictionPolicy
+	janitorInterval time.Duration
+}
+
+// WithMaxEntries limits the number of values. If the limit is reached, values
+// are evicted according to the eviction policy.
+func WithMaxEntries(n int) MemoryOption {
+	return func(o *memoryOptions) {
+		o.maxEntries = n
+	}
 }
 
-// InMemory returns a new in-memory cache.
-func InMemory() *Memory {
-	return &Memory{
-		values: make(map[string]inMemoryValue, 1),
+// WithMaxBytes limits the total size of the keys and values. If the limit is
+// reached, values are evicted according to the eviction policy. Values that
+// exceed the limit on their own are not stored.
+func WithMaxBytes(n int) MemoryOption {
+	return func(o *memoryOptions) {
+		o.maxBytes = n
 	}
 }
 
+// WithEvictionPolicy sets the policy for evicting values if the cache is
+// bounded, it defaults to EvictLRU. Unbounded caches don't track the use of
+// values.
+func WithEvictionPolicy(policy EvictionPolicy) MemoryOption {
+	return func(o *memoryOptions) {
+		o.policy = policy
+	}
+}
+
+// WithJanitorInterval starts a janitor that removes the expired values in the
+// interval. Without janitor, expired values are only removed once they are
+// read or evicted.
+func WithJanitorInterval(interval time.Duration) MemoryOption {
+	return func(o *memoryOptions) {
+		o.janitorInterval = interval
+	}
+}
+
+// WithName sets the name of the cache used as label of the metrics, it
+// defaults to "memory".
+func WithName(name string) MemoryOption {
+	return func(o *memoryOptions) {
+		o.name = name
+	}
+}
+
+// Sets the tier used as label of the metrics, it defaults to tierMemory.
+func withTier(tier string) MemoryOption {
+	return func(o *memoryOptions) {
+		o.tier = tier
+	}
+}
+
+// InMemory returns a new in-memory cache. Without options it is unbounded and
+// has no janitor.
+func InMemory(opts ...MemoryOption) *Memory {
+	o := memoryOptions{
+		name: "memory",
+		tier: tierMemory,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	m := &memory{
+		name:       o.name,
+		tier:       o.tier,
+		maxEntries: o.maxEntries,
+		maxBytes:   o.maxBytes,
+		values:     make(map[string]*inMemoryValue, 1),
+	}
+	switch {
+	case o.maxEntries <= 0 && o.maxBytes <= 0:
+	case o.policy == EvictLFU:
+		m.eviction = &lfuPolicy{}
+	default:
+		m.eviction = &lruPolicy{order: list.New()}
+	}
+
+	c := &Memory{memory: m}
+	var stop chan struct{}
+	if o.janitorInterval > 0 {
+		stop = make(chan struct{})
+		go m.janitor(o.janitorInterval, stop)
+	}
+	runtime.AddCleanup(c, m.release, stop)
+	return c
+}
+
 // Put stores the value under the key. Any existing value is overwritten. If ttl
 // is given, the cache automatically forgets the value after the duration. If
 // ttl is zero then it is never automatically forgotten.
 func (c *Memory) Put(_ context.Context, key string, value []byte, ttl time.Duration) error {
-	v := inMemoryValue{value: make([]byte, len(value))}
+	v := &inMemoryValue{key: key, value: make([]byte, len(value))}
 	copy(v.value, value)
 	if ttl != 0 {
 		v.expiresAt = time.Now().Add(ttl)
 	}
+
 	c.mx.Lock()
+	defer c.mx.Unlock()
+	if old, ok := c.values[key]; ok {
+		c.remove(old)
+	}
+	if c.maxBytes > 0 && v.size() > c.maxBytes {
+		return nil
+	}
+	// make room before adding the value, so that it is not evicted itself
+	for (c.maxEntries > 0 && len(c.values) >= c.maxEntries) || (c.maxBytes > 0 && c.bytes+v.size() > c.maxBytes) {
+		c.evict(c.eviction.victim())
+	}
+
 	c.values[key] = v
-	c.mx.Unlock()
+	c.bytes += v.size()
+	if c.eviction != nil {
+		c.clock++
+		v.lastUsed = c.clock
+		c.eviction.add(v)
+	}
+	paceCacheEntries.WithLabelValues(c.name).Inc()
+	paceCacheBytes.WithLabelValues(c.name).Add(float64(v.size()))
 	return nil
 }
 
@@ -49,18 +197,33 @@ func (c *Memory) Put(_ context.Context, key string, value []byte, ttl time.Durat
 // no value stored, ErrNotFound is returned. If the ttl is zero, the value does
 // not automatically expire. Unless an error is returned, the value is always
 // non-nil.
-func (c *Memory) Get(ctx context.Context, key string) ([]byte, time.Duration, error) {
-	c.mx.RLock()
-	v, ok := c.values[key]
-	c.mx.RUnlock()
-	if !ok {
+func (c *Memory) Get(_ context.Context, key string) ([]byte, time.Duration, error) {
+	var v *inMemoryValue
+	if c.eviction == nil {
+		// the use of the values is not tracked, so reads don't change the
+		// state of the cache
+		c.mx.RLock()
+		v = c.values[key]
+		c.mx.RUnlock()
+	} else {
+		c.mx.Lock()
+		v = c.values[key]
+		if v != nil {
+			c.clock++
+			v.uses++
+			v.lastUsed = c.clock
+			c.eviction.touch(v)
+		}
+		c.mx.Unlock()
+	}
+	if v == nil {
 		return nil, 0, fmt.Errorf("key %q: %w", key, ErrNotFound)
 	}
 	var ttl time.Duration
 	if !v.expiresAt.IsZero() {
 		ttl = time.Until(v.expiresAt)
 		if ttl <= 0 {
-			c.forget(key)
+			c.removeIfStored(v)
 			return nil, 0, fmt.Errorf("key %q: %w", key, ErrNotFound)
 		}
 	}
@@ -72,12 +235,135 @@ func (c *Memory) Get(ctx context.Context, key string) ([]byte, time.Duration, er
 // Forget removes the value stored under the key. No error is returned if there
 // is no value stored.
 func (c *Memory) Forget(_ context.Context, key string) error {
-	c.forget(key)
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	if v, ok := c.values[key]; ok {
+		c.remove(v)
+	}
 	return nil
 }
 
-func (c *Memory) forget(key string) {
+// Removes the value, unless it was overwritten or removed already.
+func (c *memory) removeIfStored(v *inMemoryValue) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	if c.values[v.key] == v {
+		c.remove(v)
+	}
+}
+
+// Must be called with the lock held.
+func (c *memory) remove(v *inMemoryValue) {
+	delete(c.values, v.key)
+	c.bytes -= v.size()
+	if c.eviction != nil {
+		c.eviction.remove(v)
+	}
+	paceCacheEntries.WithLabelValues(c.name).Dec()
+	paceCacheBytes.WithLabelValues(c.name).Sub(float64(v.size()))
+}
+
+// Like remove, but counts the value as evicted, because the cache is full.
+func (c *memory) evict(v *inMemoryValue) {
+	c.remove(v)
+	paceCacheEvictionsTotal.WithLabelValues(c.name, c.tier).Inc()
+}
+
+// Removes the expired values in the interval until stop is closed.
+func (c *memory) janitor(interval time.Duration, stop chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			c.removeExpired()
+		}
+	}
+}
+
+func (c *memory) removeExpired() {
+	now := time.Now()
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	for _, v := range c.values {
+		if !v.expiresAt.IsZero() && !now.Before(v.expiresAt) {
+			c.remove(v)
+		}
+	}
+}
+
+// Stops the janitor, if any, and removes all values, so that the gauges are
+// correct after the cache is garbage collected.
+func (c *memory) release(stop chan struct{}) {
+	if stop != nil {
+		close(stop)
+	}
+	c.clear()
+}
+
+// Removes all values.
+func (c *memory) clear() {
 	c.mx.Lock()
-	delete(c.values, key)
-	c.mx.Unlock()
+	defer c.mx.Unlock()
+	for _, v := range c.values {
+		c.remove(v)
+	}
+}
+
+// evictionPolicy tracks the use of the values of a Memory cache. It is only
+// used with the lock of the cache held.
+type evictionPolicy interface {
+	add(v *inMemoryValue)
+	touch(v *inMemoryValue)
+	remove(v *inMemoryValue)
+	// returns the value to evict next
+	victim() *inMemoryValue
+}
+
+type lruPolicy struct {
+	order *list.List // front is the most recently used
+}
+
+func (p *lruPolicy) add(v *inMemoryValue)    { v.elem = p.order.PushFront(v) }
+func (p *lruPolicy) touch(v *inMemoryValue)  { p.order.MoveToFront(v.elem) }
+func (p *lruPolicy) remove(v *inMemoryValue) { p.order.Remove(v.elem) }
+func (p *lruPolicy) victim() *inMemoryValue  { return p.order.Back().Value.(*inMemoryValue) }
+
+// lfuPolicy is a min-heap of the values ordered by their number of uses.
+type lfuPolicy []*inMemoryValue
+
+func (p *lfuPolicy) add(v *inMemoryValue)    { heap.Push(p, v) }
+func (p *lfuPolicy) touch(v *inMemoryValue)  { heap.Fix(p, v.index) }
+func (p *lfuPolicy) remove(v *inMemoryValue) { heap.Remove(p, v.index) }
+func (p *lfuPolicy) victim() *inMemoryValue  { return (*p)[0] }
+
+func (p lfuPolicy) Len() int { return len(p) }
+
+func (p lfuPolicy) Less(i, j int) bool {
+	if p[i].uses != p[j].uses {
+		return p[i].uses < p[j].uses
+	}
+	return p[i].lastUsed < p[j].lastUsed
+}
+
+func (p lfuPolicy) Swap(i, j int) {
+	p[i], p[j] = p[j], p[i]
+	p[i].index = i
+	p[j].index = j
+}
+
+func (p *lfuPolicy) Push(x any) {
+	v := x.(*inMemoryValue)
+	v.index = len(*p)
+	*p = append(*p, v)
+}
+
+func (p *lfuPolicy) Pop() any {
+	old := *p
+	v := old[len(old)-1]
+	old[len(old)-1] = nil
+	*p = old[:len(old)-1]
+	return v
 }
@@ -0,0 +1,112 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package cache
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemory_maxEntries(t *testing.T) {
+	ctx := context.Background()
+	for name, tc := range map[string]struct {
+		policy  EvictionPolicy
+		evicted string
+	}{
+		"lru": {policy: EvictLRU, evicted: "b"},
+		"lfu": {policy: EvictLFU, evicted: "c"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			c := InMemory(WithMaxEntries(3), WithEvictionPolicy(tc.policy), WithName("test_"+name))
+			evictions := testutil.ToFloat64(paceCacheEvictionsTotal.WithLabelValues("test_"+name, tierMemory))
+			require.NoError(t, c.Put(ctx, "a", []byte("1"), 0))
+			require.NoError(t, c.Put(ctx, "b", []byte("2"), 0))
+			require.NoError(t, c.Put(ctx, "c", []byte("3"), 0))
+			// b is used more often than c but less recently
+			for _, key := range []string{"b", "b", "c", "a", "a"} {
+				_, _, err := c.Get(ctx, key)
+				require.NoError(t, err)
+			}
+			require.NoError(t, c.Put(ctx, "d", []byte("4"), 0))
+
+			_, _, err := c.Get(ctx, tc.evicted)
+			require.ErrorIs(t, err, ErrNotFound)
+			require.Len(t, c.values, 3)
+			require.Equal(t, evictions+1, testutil.ToFloat64(paceCacheEvictionsTotal.WithLabelValues("test_"+name, tierMemory)))
+		})
+	}
+}
+
+func TestMemory_maxBytes(t *testing.T) {
+	ctx := context.Background()
+	c := InMemory(WithMaxBytes(10), WithName("test_bytes"))
+
+	require.NoError(t, c.Put(ctx, "a", []byte("1234"), 0))
+	require.NoError(t, c.Put(ctx, "b", []byte("1234"), 0))
+	require.Equal(t, 10, c.bytes)
+	require.NoError(t, c.Put(ctx, "c", []byte("1"), 0))
+	_, _, err := c.Get(ctx, "a")
+	require.ErrorIs(t, err, ErrNotFound)
+	require.Equal(t, 7, c.bytes)
+
+	// values exceeding the limit on their own are not stored
+	require.NoError(t, c.Put(ctx, "b", []byte("12345678910"), 0))
+	_, _, err = c.Get(ctx, "b")
+	require.ErrorIs(t, err, ErrNotFound)
+	require.Equal(t, 2, c.bytes)
+}
+
+func TestMemory_janitor(t *testing.T) {
+	ctx := context.Background()
+	c := InMemory(WithJanitorInterval(time.Millisecond), WithName("test_janitor"))
+	for i := 0; i < 10; i++ {
+		require.NoError(t, c.Put(ctx, fmt.Sprintf("key%d", i), []byte("value"), time.Millisecond))
+	}
+	require.NoError(t, c.Put(ctx, "forever", []byte("value"), 0))
+	require.Eventually(t, func() bool {
+		c.mx.Lock()
+		defer c.mx.Unlock()
+		return len(c.values) == 1
+	}, time.Second, time.Millisecond)
+
+	// the janitor stops once the cache is garbage collected
+	c = nil
+	require.Eventually(t, func() bool {
+		runtime.GC()
+		return testutil.ToFloat64(paceCacheEntries.WithLabelValues("test_janitor")) == 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestMemory_unbounded(t *testing.T) {
+	ctx := context.Background()
+	c := InMemory(WithName("test_unbounded"))
+	require.Nil(t, c.eviction)
+
+	require.NoError(t, c.Put(ctx, "a", []byte("1"), time.Millisecond))
+	require.NoError(t, c.Put(ctx, "b", []byte("2"), 0))
+	_, _, err := c.Get(ctx, "b")
+	require.NoError(t, err)
+
+	// without janitor expired values are removed once they are read
+	time.Sleep(2 * time.Millisecond)
+	require.Len(t, c.values, 2)
+	_, _, err = c.Get(ctx, "a")
+	require.ErrorIs(t, err, ErrNotFound)
+	require.Len(t, c.values, 1)
+
+	// the gauges are reset once the cache is garbage collected, even without
+	// janitor
+	require.Equal(t, 1.0, testutil.ToFloat64(paceCacheEntries.WithLabelValues("test_unbounded")))
+	c = nil
+	require.Eventually(t, func() bool {
+		runtime.GC()
+		return testutil.ToFloat64(paceCacheEntries.WithLabelValues("test_unbounded")) == 0 &&
+			testutil.ToFloat64(paceCacheBytes.WithLabelValues("test_unbounded")) == 0
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,55 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package cache
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Tiers used as label of the metrics. The tiers of the TwoTier cache are
+// local and remote, the Memory cache is a tier of its own.
+const (
+	tierLocal  = "local"
+	tierRemote = "remote"
+	tierMemory = "memory"
+)
+
+var (
+	paceCacheHitsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "pace_cache_hits_total",
+			Help: "A counter for values found in a tier of a cache.",
+		},
+		[]string{"cache", "tier"},
+	)
+	paceCacheMissesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "pace_cache_misses_total",
+			Help: "A counter for values not found in a tier of a cache.",
+		},
+		[]string{"cache", "tier"},
+	)
+	paceCacheEvictionsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "pace_cache_evictions_total",
+			Help: "A counter for values evicted from a tier of a cache because it was full.",
+		},
+		[]string{"cache", "tier"},
+	)
+	paceCacheEntries = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "pace_cache_entries",
+			Help: "A gauge of values stored in a memory cache.",
+		},
+		[]string{"cache"},
+	)
+	paceCacheBytes = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "pace_cache_bytes",
+			Help: "A gauge of the size of the keys and values stored in a memory cache.",
+		},
+		[]string{"cache"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(paceCacheHitsTotal, paceCacheMissesTotal, paceCacheEvictionsTotal, paceCacheEntries, paceCacheBytes)
+}
@@ -0,0 +1,185 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package cache
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/uptrace/bun"
+
+	pberrors "github.com/pace/bricks/maintenance/errors"
+	"github.com/pace/bricks/maintenance/log"
+	pglock "github.com/pace/bricks/pkg/lock/postgres"
+	"github.com/pace/bricks/pkg/routine"
+)
+
+var _ Cache = (*Postgres)(nil)
+
+// Interval in which the expired values are deleted from the table.
+const postgresPurgeInterval = time.Minute
+
+// Postgres is the cache that stores the values in a table of a postgres
+// database. It is safe for concurrent use.
+type Postgres struct {
+	db    *bun.DB
+	table string
+
+	mx      sync.Mutex
+	created bool
+	cancel  context.CancelFunc
+}
+
+// InPostgres returns a new cache that stores the values in the given table.
+// The table is created on first use if it doesn't exist. Expired values are
+// ignored, InPostgres starts a background routine that deletes them from the
+// table every minute. The routine runs in a single instance per table across
+// all processes sharing the database, it is coordinated by a postgres
+// advisory lock, so the cache doesn't depend on redis. Close stops the
+// routine.
+func InPostgres(db *bun.DB, table string) *Postgres {
+	c := &Postgres{
+		db:    db,
+		table: table,
+	}
+	c.cancel = routine.RunNamed(log.WithContext(context.Background()), "cache:postgres:"+table,
+		c.purgeExpired, routine.KeepRunningOneInstance(), routine.Locker(pglock.NewLocker(db)))
+	return c
+}
+
+// Close stops the routine that deletes the expired values. The cache must not
+// be used afterwards.
+func (c *Postgres) Close() {
+	c.cancel()
+}
+
+// Put stores the value under the key. Any existing value is overwritten. If ttl
+// is given, the cache automatically forgets the value after the duration. If
+// ttl is zero then it is never automatically forgotten.
+func (c *Postgres) Put(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if err := c.createTable(ctx); err != nil {
+		return err
+	}
+	if value == nil {
+		value = []byte{}
+	}
+	expiresAt := bun.Safe("NULL")
+	if ttl != 0 {
+		// use the clock of the database, so that the ttl is independent of
+		// the clocks of the processes
+		expiresAt = bun.Safe(fmt.Sprintf("clock_timestamp() + interval '%d microseconds'", ttl.Microseconds()))
+	}
+	_, err := c.db.ExecContext(ctx, `INSERT INTO ? (key, value, expires_at) VALUES (?, ?, ?)
+		ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, expires_at = EXCLUDED.expires_at`,
+		bun.Ident(c.table), key, value, expiresAt)
+	if err != nil {
+		return fmt.Errorf("%w: postgres: %s", ErrBackend, err)
+	}
+	return nil
+}
+
+// Get returns the value stored under the key and its remaining ttl. If there is
+// no value stored, ErrNotFound is returned. If the ttl is zero, the value does
+// not automatically expire. Unless an error is returned, the value is always
+// non-nil.
+func (c *Postgres) Get(ctx context.Context, key string) ([]byte, time.Duration, error) {
+	if err := c.createTable(ctx); err != nil {
+		return nil, 0, err
+	}
+	var (
+		value []byte
+		ttl   sql.NullInt64 // in microseconds
+	)
+	err := c.db.QueryRowContext(ctx, `SELECT value, (EXTRACT(EPOCH FROM expires_at - clock_timestamp()) * 1000000)::bigint
+		FROM ? WHERE key = ? AND (expires_at IS NULL OR expires_at > clock_timestamp())`,
+		bun.Ident(c.table), key).Scan(&value, &ttl)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, 0, fmt.Errorf("key %q: %w", key, ErrNotFound)
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: postgres: %s", ErrBackend, err)
+	}
+	if value == nil {
+		value = []byte{}
+	}
+	switch {
+	case !ttl.Valid: // no expiry
+		return value, 0, nil
+	case ttl.Int64 <= 0: // about to expire this microsecond
+		return value, time.Duration(1), nil // use smallest non-zero duration
+	default:
+		return value, time.Duration(ttl.Int64) * time.Microsecond, nil
+	}
+}
+
+// Forget removes the value stored under the key. No error is returned if there
+// is no value stored.
+func (c *Postgres) Forget(ctx context.Context, key string) error {
+	if err := c.createTable(ctx); err != nil {
+		return err
+	}
+	_, err := c.db.ExecContext(ctx, "DELETE FROM ? WHERE key = ?", bun.Ident(c.table), key)
+	if err != nil {
+		return fmt.Errorf("%w: postgres: %s", ErrBackend, err)
+	}
+	return nil
+}
+
+// Creates the table unless it was created before by this cache.
+func (c *Postgres) createTable(ctx context.Context) error {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	if c.created {
+		return nil
+	}
+	_, err := c.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS ? (
+		key text PRIMARY KEY,
+		value bytea NOT NULL,
+		expires_at timestamptz
+	)`, bun.Ident(c.table))
+	if err != nil {
+		return fmt.Errorf("%w: postgres: failed to create table %q: %s", ErrBackend, c.table, err)
+	}
+	_, err = c.db.ExecContext(ctx, "CREATE INDEX IF NOT EXISTS ? ON ? (expires_at)",
+		bun.Ident(c.table+"_expires_at_idx"), bun.Ident(c.table))
+	if err != nil {
+		return fmt.Errorf("%w: postgres: failed to create index of table %q: %s", ErrBackend, c.table, err)
+	}
+	c.created = true
+	return nil
+}
+
+// Deletes the expired values regularly until the context is done.
+func (c *Postgres) purgeExpired(ctx context.Context) {
+	for {
+		n, err := c.purge(ctx)
+		if err != nil {
+			log.Ctx(ctx).Debug().Err(err).Str("table", c.table).Msg("cache: could not purge expired values")
+			pberrors.Handle(ctx, err)
+		} else if n > 0 {
+			log.Ctx(ctx).Debug().Int64("count", n).Str("table", c.table).Msg("cache: purged expired values")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(postgresPurgeInterval):
+		}
+	}
+}
+
+// Deletes the expired values and returns their number.
+func (c *Postgres) purge(ctx context.Context) (int64, error) {
+	if err := c.createTable(ctx); err != nil {
+		return 0, err
+	}
+	res, err := c.db.ExecContext(ctx, "DELETE FROM ? WHERE expires_at <= clock_timestamp()", bun.Ident(c.table))
+	if err != nil {
+		return 0, fmt.Errorf("%w: postgres: %s", ErrBackend, err)
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,38 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pace/bricks/backend/postgres"
+	"github.com/pace/bricks/maintenance/log"
+)
+
+func TestIntegrationPostgres_purge(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	ctx := log.WithContext(context.Background())
+	db := postgres.NewDB(ctx)
+	c := InPostgres(db, "test_cache_purge")
+	defer c.Close()
+
+	require.NoError(t, c.Put(ctx, "expired", []byte("bar"), time.Millisecond))
+	require.NoError(t, c.Put(ctx, "valid", []byte("bar"), time.Hour))
+	time.Sleep(2 * time.Millisecond)
+
+	_, err := c.purge(ctx)
+	require.NoError(t, err)
+	// the expired row is deleted, not only ignored
+	count, err := db.NewSelect().Table("test_cache_purge").Where("key = ?", "expired").Count(ctx)
+	require.NoError(t, err)
+	require.Zero(t, count)
+	_, _, err = c.Get(ctx, "valid")
+	require.NoError(t, err)
+	require.NoError(t, c.Forget(ctx, "valid"))
+}
@@ -0,0 +1,26 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package cache_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/pace/bricks/backend/postgres"
+	"github.com/pace/bricks/maintenance/log"
+	"github.com/pace/bricks/pkg/cache"
+	"github.com/pace/bricks/pkg/cache/testsuite"
+)
+
+func TestIntegrationPostgres(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	c := cache.InPostgres(postgres.NewDB(log.WithContext(context.Background())), "test_cache")
+	defer c.Close()
+	suite.Run(t, &testsuite.CacheTestSuite{
+		Cache: c,
+	})
+}
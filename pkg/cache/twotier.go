@@ -0,0 +1,317 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package cache
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/xid"
+
+	"github.com/pace/bricks/maintenance/log"
+	"github.com/pace/bricks/pkg/routine"
+)
+
+var _ Cache = (*TwoTier)(nil)
+
+// Defaults of the local tier of the TwoTier cache.
+const (
+	DefaultLocalSize = 1000
+	DefaultLocalTTL  = time.Minute
+)
+
+// TwoTier is the cache that reads through a bounded in-memory cache in front
+// of a redis cache. Changes are broadcast via redis pub/sub, so that all
+// processes using the same redis and prefix remove their local copy. It is
+// safe for concurrent use.
+//
+// Invalidations may get lost while the subscription is interrupted, all local
+// values are removed once it is established again. In any case, local values
+// are used at most for the local ttl, see WithLocalTTL.
+type TwoTier struct {
+	remote  *Redis
+	client  *redis.Client
+	prefix  string
+	channel string
+	id      string // identifies the invalidations sent by this cache
+
+	local     *Memory
+	localSize int
+	localTTL  time.Duration
+	loads     loads
+
+	cancel context.CancelFunc
+}
+
+// TwoTierOption configures a TwoTier cache.
+type TwoTierOption func(*TwoTier)
+
+// WithLocalSize sets the maximum number of values in the local tier, it
+// defaults to DefaultLocalSize. The least recently used values are evicted
+// first, see InMemory.
+func WithLocalSize(size int) TwoTierOption {
+	return func(c *TwoTier) {
+		c.localSize = size
+	}
+}
+
+// WithLocalTTL sets the maximum duration a value is used from the local tier,
+// it defaults to DefaultLocalTTL. Values with a shorter ttl are used until
+// they expire.
+func WithLocalTTL(ttl time.Duration) TwoTierOption {
+	return func(c *TwoTier) {
+		c.localTTL = ttl
+	}
+}
+
+// InTwoTiers returns a new cache that uses a local in-memory tier in front of
+// redis using the given client. The prefix is used for every key that is
+// stored in redis and for the channel of the invalidations. The subscription
+// to the invalidations runs until Close is called.
+func InTwoTiers(client *redis.Client, prefix string, opts ...TwoTierOption) *TwoTier {
+	c := &TwoTier{
+		remote:    InRedis(client, prefix),
+		client:    client,
+		prefix:    prefix,
+		channel:   prefix + "invalidations",
+		id:        xid.New().String(),
+		localSize: DefaultLocalSize,
+		localTTL:  DefaultLocalTTL,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.local = InMemory(WithMaxEntries(c.localSize), WithName(c.prefix), withTier(tierLocal))
+	c.cancel = routine.Run(log.WithContext(context.Background()), c.subscribe)
+	return c
+}
+
+// Put stores the value under the key. Any existing value is overwritten. If ttl
+// is given, the cache automatically forgets the value after the duration. If
+// ttl is zero then it is never automatically forgotten.
+func (c *TwoTier) Put(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	// loads that began before the value was written must not store it
+	err := c.remote.Put(ctx, key, value, ttl)
+	c.loads.invalidate(key)
+	if err != nil {
+		c.local.Forget(ctx, key) // nolint: errcheck
+		return err
+	}
+	c.putLocal(ctx, key, value, ttl)
+	return c.invalidate(ctx, key)
+}
+
+// Get returns the value stored under the key and its remaining ttl. If there is
+// no value stored, ErrNotFound is returned. If the ttl is zero, the value does
+// not automatically expire. Unless an error is returned, the value is always
+// non-nil.
+func (c *TwoTier) Get(ctx context.Context, key string) ([]byte, time.Duration, error) {
+	if local, _, err := c.local.Get(ctx, key); err == nil {
+		paceCacheHitsTotal.WithLabelValues(c.prefix, tierLocal).Inc()
+		value, expiresAt := decodeLocal(local)
+		var ttl time.Duration
+		if !expiresAt.IsZero() {
+			ttl = max(time.Until(expiresAt), time.Duration(1)) // use smallest non-zero duration
+		}
+		return value, ttl, nil
+	}
+	paceCacheMissesTotal.WithLabelValues(c.prefix, tierLocal).Inc()
+
+	load := c.loads.begin(key)
+	value, ttl, err := c.remote.Get(ctx, key)
+	if err != nil {
+		c.loads.end(load, nil)
+	}
+	if errors.Is(err, ErrNotFound) {
+		paceCacheMissesTotal.WithLabelValues(c.prefix, tierRemote).Inc()
+		return nil, 0, err
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	paceCacheHitsTotal.WithLabelValues(c.prefix, tierRemote).Inc()
+	c.loads.end(load, func() {
+		c.putLocal(ctx, key, value, ttl)
+	})
+	return value, ttl, nil
+}
+
+// Forget removes the value stored under the key. No error is returned if there
+// is no value stored.
+func (c *TwoTier) Forget(ctx context.Context, key string) error {
+	err := c.remote.Forget(ctx, key)
+	c.removeLocal(key)
+	if err != nil {
+		return err
+	}
+	return c.invalidate(ctx, key)
+}
+
+// Removes the local copy of the key, values of the key that are being loaded
+// are not stored locally.
+func (c *TwoTier) removeLocal(key string) {
+	c.loads.invalidate(key)
+	c.local.Forget(context.Background(), key) // nolint: errcheck
+}
+
+// Removes all local values, values that are being loaded are not stored
+// locally.
+func (c *TwoTier) purgeLocal() {
+	c.loads.invalidateAll()
+	c.local.clear()
+}
+
+// Stores a copy of the value locally. It is used at most for the local ttl,
+// or for the ttl if it is shorter and not zero.
+func (c *TwoTier) putLocal(ctx context.Context, key string, value []byte, ttl time.Duration) {
+	localTTL := c.localTTL
+	if ttl != 0 && ttl < localTTL {
+		localTTL = ttl
+	}
+	c.local.Put(ctx, key, encodeLocal(value, ttl), localTTL) // nolint: errcheck
+}
+
+// Local values are prefixed with the time they expire in redis, in unix
+// nanoseconds, zero if they don't expire.
+func encodeLocal(value []byte, ttl time.Duration) []byte {
+	var expiresAt int64
+	if ttl != 0 {
+		expiresAt = time.Now().Add(ttl).UnixNano()
+	}
+	local := binary.BigEndian.AppendUint64(make([]byte, 0, 8+len(value)), uint64(expiresAt))
+	return append(local, value...)
+}
+
+func decodeLocal(local []byte) (value []byte, expiresAt time.Time) {
+	if nanos := int64(binary.BigEndian.Uint64(local)); nanos != 0 {
+		expiresAt = time.Unix(0, nanos)
+	}
+	return local[8:], expiresAt
+}
+
+// Close stops the subscription to the invalidations and removes the local
+// values. The cache must not be used afterwards.
+func (c *TwoTier) Close() {
+	c.cancel()
+	c.local.clear()
+}
+
+// Tells the other caches to remove their local copy of the key.
+func (c *TwoTier) invalidate(ctx context.Context, key string) error {
+	err := c.client.Publish(ctx, c.channel, c.id+"\n"+key).Err()
+	if err != nil {
+		return fmt.Errorf("%w: redis: failed to publish invalidation: %s", ErrBackend, err)
+	}
+	return nil
+}
+
+// Removes the local copies of the keys invalidated by other caches until the
+// context is done.
+func (c *TwoTier) subscribe(ctx context.Context) {
+	pubsub := c.client.Subscribe(ctx, c.channel)
+	defer pubsub.Close() // nolint: errcheck
+
+	for {
+		msg, err := pubsub.Receive(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			// invalidations may be missed until the subscription is
+			// established again
+			c.purgeLocal()
+			log.Ctx(ctx).Debug().Err(err).Str("channel", c.channel).Msg("cache: could not receive invalidations")
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+
+		switch msg := msg.(type) {
+		case *redis.Subscription:
+			// the values may have changed while not subscribed
+			c.purgeLocal()
+		case *redis.Message:
+			id, key, ok := strings.Cut(msg.Payload, "\n")
+			if ok && id != c.id {
+				c.removeLocal(key)
+			}
+		}
+	}
+}
+
+// loads tracks the values that are being loaded from redis, so that values
+// that were invalidated while being loaded are not stored locally. Only keys
+// that are being loaded are tracked.
+type loads struct {
+	mx      sync.Mutex
+	epoch   uint64 // incremented by invalidateAll
+	pending map[string]*pendingLoads
+}
+
+type pendingLoads struct {
+	count   int    // number of loads of the key
+	version uint64 // incremented by invalidate
+}
+
+type loadToken struct {
+	key            string
+	epoch, version uint64
+}
+
+// Registers a load of the key, it must be ended using end.
+func (l *loads) begin(key string) loadToken {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+	if l.pending == nil {
+		l.pending = make(map[string]*pendingLoads)
+	}
+	p, ok := l.pending[key]
+	if !ok {
+		p = &pendingLoads{}
+		l.pending[key] = p
+	}
+	p.count++
+	return loadToken{key: key, epoch: l.epoch, version: p.version}
+}
+
+// Ends the load and calls store, if not nil and the key was not invalidated
+// since the load began. Invalidations wait for store to return.
+func (l *loads) end(t loadToken, store func()) {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+	p := l.pending[t.key]
+	if store != nil && t.epoch == l.epoch && t.version == p.version {
+		store()
+	}
+	p.count--
+	if p.count == 0 {
+		delete(l.pending, t.key)
+	}
+}
+
+// Invalidates the loads of the key, it must be called before the local value
+// is removed.
+func (l *loads) invalidate(key string) {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+	if p, ok := l.pending[key]; ok {
+		p.version++
+	}
+}
+
+// Invalidates all loads, it must be called before the local values are
+// removed.
+func (l *loads) invalidateAll() {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+	l.epoch++
+}
@@ -0,0 +1,55 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeLocal(t *testing.T) {
+	value, expiresAt := decodeLocal(encodeLocal([]byte("value"), 0))
+	require.Equal(t, []byte("value"), value)
+	require.True(t, expiresAt.IsZero())
+
+	value, expiresAt = decodeLocal(encodeLocal([]byte{}, time.Hour))
+	require.Empty(t, value)
+	require.WithinDuration(t, time.Now().Add(time.Hour), expiresAt, time.Second)
+}
+
+func TestLoads(t *testing.T) {
+	var l loads
+	stored := 0
+	store := func() { stored++ }
+
+	// loads of invalidated keys don't store
+	load := l.begin("a")
+	l.invalidate("a")
+	l.end(load, store)
+	require.Zero(t, stored)
+
+	// loads of other keys are independent
+	load = l.begin("a")
+	l.invalidate("b")
+	l.end(load, store)
+	require.Equal(t, 1, stored)
+
+	// concurrent loads of the same key
+	first, second := l.begin("a"), l.begin("a")
+	l.invalidate("a")
+	third := l.begin("a")
+	l.end(first, store)
+	l.end(second, store)
+	l.end(third, store)
+	require.Equal(t, 2, stored)
+
+	load = l.begin("a")
+	l.invalidateAll()
+	l.end(load, store)
+	require.Equal(t, 2, stored)
+
+	// only keys that are being loaded are tracked
+	require.Empty(t, l.pending)
+}
@@ -0,0 +1,55 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/pace/bricks/backend/redis"
+	"github.com/pace/bricks/maintenance/log"
+	"github.com/pace/bricks/pkg/cache"
+	"github.com/pace/bricks/pkg/cache/testsuite"
+)
+
+func TestIntegrationTwoTier(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	c := cache.InTwoTiers(redis.Client(), "test:cache:twotier:")
+	defer c.Close()
+	suite.Run(t, &testsuite.CacheTestSuite{Cache: c})
+}
+
+func TestIntegrationTwoTier_invalidation(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	ctx := log.WithContext(context.Background())
+	a := cache.InTwoTiers(redis.Client(), "test:cache:invalidation:")
+	defer a.Close()
+	b := cache.InTwoTiers(redis.Client(), "test:cache:invalidation:")
+	defer b.Close()
+	time.Sleep(100 * time.Millisecond) // wait for the subscriptions
+
+	require.NoError(t, a.Put(ctx, "foo", []byte("bar"), 0))
+	value, _, err := b.Get(ctx, "foo") // b has a local copy now
+	require.NoError(t, err)
+	require.Equal(t, []byte("bar"), value)
+
+	require.NoError(t, a.Put(ctx, "foo", []byte("baz"), 0))
+	require.Eventually(t, func() bool {
+		value, _, err := b.Get(ctx, "foo")
+		return err == nil && string(value) == "baz"
+	}, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, a.Forget(ctx, "foo"))
+	require.Eventually(t, func() bool {
+		_, _, err := b.Get(ctx, "foo")
+		return err != nil
+	}, time.Second, 10*time.Millisecond)
+}
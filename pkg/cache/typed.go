@@ -0,0 +1,209 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package cache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Flags of the header byte that precedes every value of a Typed cache.
+const (
+	typedCompressed byte = 1 << iota
+	typedEncrypted
+)
+
+// Typed is the cache that stores values of type T in another cache, encoded
+// with a codec. It is safe for concurrent use.
+//
+// Every value is stored with a header, so that values written with other
+// compression settings can be read. Values that can't be decrypted or
+// decoded are reported as ErrInvalidValue.
+type Typed[T any] struct {
+	cache   Cache
+	codec   Codec
+	version string
+
+	compressAbove int // no compression if negative
+	aeads         []cipher.AEAD
+}
+
+// TypedOption configures a Typed cache.
+type TypedOption func(*typedOptions)
+
+type typedOptions struct {
+	version       string
+	compressAbove int
+	keys          [][]byte
+}
+
+// WithVersion prefixes all keys with the version. Change it on incompatible
+// changes of the type, so that values of the old type are not decoded.
+func WithVersion(version string) TypedOption {
+	return func(o *typedOptions) {
+		o.version = version
+	}
+}
+
+// WithCompression compresses encoded values that are larger than the given
+// number of bytes using gzip.
+func WithCompression(above int) TypedOption {
+	return func(o *typedOptions) {
+		o.compressAbove = above
+	}
+}
+
+// WithEncryption encrypts values using AES-GCM with the key, which must be 16,
+// 24 or 32 bytes long. Values encrypted with one of the old keys can still be
+// read, which allows rotating keys. The key of the value is authenticated
+// along with the value, so that values can't be swapped. NewTyped panics if a
+// key is invalid.
+func WithEncryption(key []byte, oldKeys ...[]byte) TypedOption {
+	return func(o *typedOptions) {
+		o.keys = append([][]byte{key}, oldKeys...)
+	}
+}
+
+// NewTyped returns a new cache storing values of type T in c.
+func NewTyped[T any](c Cache, codec Codec, opts ...TypedOption) *Typed[T] {
+	o := typedOptions{compressAbove: -1}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	t := &Typed[T]{
+		cache:         c,
+		codec:         codec,
+		compressAbove: o.compressAbove,
+	}
+	if o.version != "" {
+		t.version = o.version + ":"
+	}
+	for _, key := range o.keys {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			panic(fmt.Errorf("cache: invalid encryption key: %w", err))
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			panic(fmt.Errorf("cache: invalid encryption key: %w", err))
+		}
+		t.aeads = append(t.aeads, aead)
+	}
+	return t
+}
+
+// Put stores the value under the key. Any existing value is overwritten. If ttl
+// is given, the cache automatically forgets the value after the duration. If
+// ttl is zero then it is never automatically forgotten.
+func (c *Typed[T]) Put(ctx context.Context, key string, value T, ttl time.Duration) error {
+	key = c.version + key
+	data, err := c.encode(key, value)
+	if err != nil {
+		return fmt.Errorf("key %q: %w", key, err)
+	}
+	return c.cache.Put(ctx, key, data, ttl)
+}
+
+// Get returns the value stored under the key and its remaining ttl. If there is
+// no value stored, ErrNotFound is returned. If the ttl is zero, the value does
+// not automatically expire.
+func (c *Typed[T]) Get(ctx context.Context, key string) (T, time.Duration, error) {
+	var value T
+	key = c.version + key
+	data, ttl, err := c.cache.Get(ctx, key)
+	if err != nil {
+		return value, 0, err
+	}
+	if value, err = c.decode(key, data); err != nil {
+		return value, 0, fmt.Errorf("key %q: %w: %s", key, ErrInvalidValue, err)
+	}
+	return value, ttl, nil
+}
+
+// Forget removes the value stored under the key. No error is returned if there
+// is no value stored.
+func (c *Typed[T]) Forget(ctx context.Context, key string) error {
+	return c.cache.Forget(ctx, c.version+key)
+}
+
+func (c *Typed[T]) encode(key string, value T) ([]byte, error) {
+	data, err := c.codec.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var flags byte
+	if c.compressAbove >= 0 && len(data) > c.compressAbove {
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		data = buf.Bytes()
+		flags |= typedCompressed
+	}
+	if len(c.aeads) > 0 {
+		aead := c.aeads[0]
+		nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(data)+aead.Overhead())
+		if _, err := rand.Read(nonce); err != nil {
+			return nil, err
+		}
+		data = aead.Seal(nonce, nonce, data, []byte(key))
+		flags |= typedEncrypted
+	}
+	return append([]byte{flags}, data...), nil
+}
+
+func (c *Typed[T]) decode(key string, data []byte) (value T, err error) {
+	if len(data) == 0 {
+		return value, errors.New("missing header")
+	}
+	flags, data := data[0], data[1:]
+
+	if flags&typedEncrypted != 0 {
+		if data, err = c.decrypt(key, data); err != nil {
+			return value, err
+		}
+	} else if len(c.aeads) > 0 {
+		return value, errors.New("value is not encrypted")
+	}
+	if flags&typedCompressed != 0 {
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return value, err
+		}
+		if data, err = io.ReadAll(r); err != nil {
+			return value, err
+		}
+	}
+	err = c.codec.Unmarshal(data, &value)
+	return value, err
+}
+
+func (c *Typed[T]) decrypt(key string, data []byte) ([]byte, error) {
+	if len(c.aeads) == 0 {
+		return nil, errors.New("value is encrypted but no key is configured")
+	}
+	for _, aead := range c.aeads {
+		if len(data) < aead.NonceSize() {
+			return nil, errors.New("value is too short")
+		}
+		nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+		if plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(key)); err == nil {
+			return plaintext, nil
+		}
+	}
+	return nil, errors.New("value could not be decrypted with any key")
+}
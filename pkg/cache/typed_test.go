@@ -0,0 +1,118 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package cache_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/pace/bricks/maintenance/log"
+	"github.com/pace/bricks/pkg/cache"
+)
+
+type typedValue struct {
+	Name  string
+	Count int
+}
+
+func TestTyped_codecs(t *testing.T) {
+	ctx := log.WithContext(context.Background())
+	for name, codec := range map[string]cache.Codec{
+		"json":    cache.JSONCodec,
+		"gob":     cache.GobCodec,
+		"msgpack": cache.MsgpackCodec,
+	} {
+		t.Run(name, func(t *testing.T) {
+			c := cache.NewTyped[typedValue](cache.InMemory(), codec)
+			require.NoError(t, c.Put(ctx, "foo", typedValue{Name: "bar", Count: 3}, time.Hour))
+			value, ttl, err := c.Get(ctx, "foo")
+			require.NoError(t, err)
+			require.Equal(t, typedValue{Name: "bar", Count: 3}, value)
+			require.Greater(t, ttl, time.Duration(0))
+		})
+	}
+
+	t.Run("protobuf", func(t *testing.T) {
+		c := cache.NewTyped[*wrapperspb.StringValue](cache.InMemory(), cache.ProtobufCodec)
+		require.NoError(t, c.Put(ctx, "foo", wrapperspb.String("bar"), 0))
+		value, _, err := c.Get(ctx, "foo")
+		require.NoError(t, err)
+		require.True(t, proto.Equal(wrapperspb.String("bar"), value))
+	})
+}
+
+func TestTyped_version(t *testing.T) {
+	ctx := log.WithContext(context.Background())
+	backend := cache.InMemory()
+	v1 := cache.NewTyped[string](backend, cache.JSONCodec, cache.WithVersion("v1"))
+	v2 := cache.NewTyped[int](backend, cache.JSONCodec, cache.WithVersion("v2"))
+
+	require.NoError(t, v1.Put(ctx, "foo", "bar", 0))
+	_, _, err := v2.Get(ctx, "foo")
+	require.ErrorIs(t, err, cache.ErrNotFound)
+	_, _, err = backend.Get(ctx, "v1:foo")
+	require.NoError(t, err)
+}
+
+func TestTyped_compression(t *testing.T) {
+	ctx := log.WithContext(context.Background())
+	backend := cache.InMemory()
+	c := cache.NewTyped[string](backend, cache.JSONCodec, cache.WithCompression(100))
+
+	require.NoError(t, c.Put(ctx, "small", "bar", 0))
+	raw, _, err := backend.Get(ctx, "small")
+	require.NoError(t, err)
+	require.Equal(t, `"bar"`, string(raw[1:]))
+
+	large := strings.Repeat("bar", 100)
+	require.NoError(t, c.Put(ctx, "large", large, 0))
+	raw, _, err = backend.Get(ctx, "large")
+	require.NoError(t, err)
+	require.Less(t, len(raw), len(large))
+	value, _, err := c.Get(ctx, "large")
+	require.NoError(t, err)
+	require.Equal(t, large, value)
+
+	// values can be read regardless of the compression settings
+	value, _, err = cache.NewTyped[string](backend, cache.JSONCodec).Get(ctx, "large")
+	require.NoError(t, err)
+	require.Equal(t, large, value)
+}
+
+func TestTyped_encryption(t *testing.T) {
+	ctx := log.WithContext(context.Background())
+	backend := cache.InMemory()
+	oldKey := bytes.Repeat([]byte{1}, 32)
+	newKey := bytes.Repeat([]byte{2}, 32)
+
+	old := cache.NewTyped[string](backend, cache.JSONCodec, cache.WithEncryption(oldKey), cache.WithCompression(0))
+	require.NoError(t, old.Put(ctx, "foo", "secret", 0))
+	raw, _, err := backend.Get(ctx, "foo")
+	require.NoError(t, err)
+	require.NotContains(t, string(raw), "secret")
+
+	// old keys can be used for decryption
+	rotated := cache.NewTyped[string](backend, cache.JSONCodec, cache.WithEncryption(newKey, oldKey))
+	value, _, err := rotated.Get(ctx, "foo")
+	require.NoError(t, err)
+	require.Equal(t, "secret", value)
+
+	_, _, err = cache.NewTyped[string](backend, cache.JSONCodec, cache.WithEncryption(newKey)).Get(ctx, "foo")
+	require.ErrorIs(t, err, cache.ErrInvalidValue)
+
+	// values are bound to their key
+	require.NoError(t, backend.Put(ctx, "bar", raw, 0))
+	_, _, err = old.Get(ctx, "bar")
+	require.ErrorIs(t, err, cache.ErrInvalidValue)
+
+	require.Panics(t, func() {
+		cache.NewTyped[string](backend, cache.JSONCodec, cache.WithEncryption([]byte("short")))
+	})
+}
@@ -0,0 +1,46 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+// Package lock defines distributed locks that are implemented by the
+// packages redis, using a redis database, and postgres, using postgres
+// advisory locks.
+package lock
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrCouldNotLock    = errors.New("lock could not be obtained")
+	ErrCouldNotRelease = errors.New("lock could not be released")
+)
+
+// Locker creates locks of a backend.
+type Locker interface {
+	// NewLock returns the lock with the name. The ttl is the duration after
+	// which the lock is released if its holder stops responding.
+	NewLock(name string, ttl time.Duration) Lock
+}
+
+// Lock is a named lock that is shared by all processes using the same
+// backend. A Lock must not be acquired concurrently, use a Lock per goroutine
+// instead.
+type Lock interface {
+	// Acquire tries to acquire the lock once and returns whether it was
+	// acquired.
+	Acquire(ctx context.Context) (bool, error)
+
+	// AcquireWait waits for the lock until it is acquired or the context is
+	// done. If the context has no deadline, it waits at most for the ttl.
+	AcquireWait(ctx context.Context) error
+
+	// AcquireAndKeepUp tries to acquire the lock once and keeps it up until
+	// cancel is called. The returned context is done once the lock is lost
+	// or released. It returns a nil context if the lock is held by another
+	// process.
+	AcquireAndKeepUp(ctx context.Context) (context.Context, context.CancelFunc, error)
+
+	// Release releases the lock acquired by Acquire or AcquireWait.
+	Release(ctx context.Context) error
+}
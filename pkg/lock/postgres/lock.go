@@ -0,0 +1,221 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+// Package postgres implements lock.Locker using postgres advisory locks.
+//
+// The locks are session locks, so every held lock occupies a connection of
+// the pool of the database until it is released. If the connection is lost,
+// postgres releases the lock automatically.
+package postgres
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/uptrace/bun"
+
+	pberrors "github.com/pace/bricks/maintenance/errors"
+	"github.com/pace/bricks/maintenance/log"
+	"github.com/pace/bricks/pkg/lock"
+)
+
+var (
+	ErrCouldNotLock    = lock.ErrCouldNotLock
+	ErrCouldNotRelease = lock.ErrCouldNotRelease
+)
+
+var (
+	_ lock.Locker = (*Locker)(nil)
+	_ lock.Lock   = (*Lock)(nil)
+)
+
+// Locker creates postgres advisory locks, see lock.Locker.
+type Locker struct {
+	db *bun.DB
+}
+
+// NewLocker returns a locker creating locks in the database.
+func NewLocker(db *bun.DB) *Locker {
+	return &Locker{db: db}
+}
+
+// NewLock returns the lock with the name and ttl.
+func (l *Locker) NewLock(name string, ttl time.Duration) lock.Lock {
+	return NewLock(l.db, name, ttl)
+}
+
+// Lock is a postgres advisory lock. The name is hashed to the 64 bit key of
+// the advisory lock.
+type Lock struct {
+	Name string
+
+	db  *bun.DB
+	key int64
+	ttl time.Duration
+
+	conn  *bun.Conn
+	mutex sync.Mutex
+}
+
+// NewLock returns the lock with the name. The ttl is the time after which a
+// lost connection is detected by AcquireAndKeepUp, the connection is checked
+// five times per ttl.
+func NewLock(db *bun.DB, name string, ttl time.Duration) *Lock {
+	h := fnv.New64a()
+	h.Write([]byte(name)) // nolint: errcheck
+	return &Lock{
+		Name: name,
+		db:   db,
+		key:  int64(h.Sum64()),
+		ttl:  ttl,
+	}
+}
+
+func (l *Lock) Acquire(ctx context.Context) (bool, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	conn, err := l.obtain(ctx)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Str("lockName", l.Name).Msg("Could not acquire lock")
+		return false, pberrors.Hide(ctx, err, ErrCouldNotLock)
+	}
+	if conn == nil {
+		return false, nil
+	}
+
+	l.conn = conn
+	return true, nil
+}
+
+func (l *Lock) AcquireWait(ctx context.Context) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	// make sure we don't wait forever
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, l.ttl)
+		defer cancel()
+	}
+
+	for {
+		conn, err := l.obtain(ctx)
+		if err != nil {
+			log.Ctx(ctx).Debug().Err(err).Str("lockName", l.Name).Msg("Could not acquire lock")
+			return pberrors.Hide(ctx, err, ErrCouldNotLock)
+		}
+		if conn != nil {
+			l.conn = conn
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return pberrors.Hide(ctx, ctx.Err(), ErrCouldNotLock)
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// AcquireAndKeepUp will acquire a lock, and keep it up until cancel is
+// called. The connection of the lock is checked regularly, the returned
+// context is cancelled once it is lost.
+func (l *Lock) AcquireAndKeepUp(ctx context.Context) (context.Context, context.CancelFunc, error) {
+	conn, err := l.obtain(ctx)
+	if err != nil {
+		return nil, nil, pberrors.Hide(ctx, err, ErrCouldNotLock)
+	}
+	if conn == nil {
+		return nil, nil, nil
+	}
+
+	lockCtx, cancelLock := context.WithCancel(ctx)
+	go func() {
+		defer pberrors.HandleWithCtx(ctx, fmt.Sprintf("keep up lock %q", l.Name)) // handle panics
+		defer cancelLock()
+
+		l.keepAlive(lockCtx, conn)
+		if err := l.release(context.WithoutCancel(ctx), conn); err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msgf("could not release lock %q", l.Name)
+		}
+	}()
+
+	return lockCtx, cancelLock, nil
+}
+
+func (l *Lock) Release(ctx context.Context) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.conn == nil {
+		log.Ctx(ctx).Debug().Msg("tried to unlock a lock that does not exist")
+		return nil
+	}
+
+	err := l.release(ctx, l.conn)
+	l.conn = nil
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("error releasing postgres lock")
+		return pberrors.Hide(ctx, err, ErrCouldNotRelease)
+	}
+	return nil
+}
+
+// Returns the connection holding the lock, or nil if the lock is held by
+// another session.
+func (l *Lock) obtain(ctx context.Context) (*bun.Conn, error) {
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var locked bool
+	err = conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock(?)", l.key).Scan(&locked)
+	if err != nil || !locked {
+		_ = conn.Close()
+		return nil, err
+	}
+	return &conn, nil
+}
+
+// Checks the connection regularly until the context is done or the
+// connection is lost.
+func (l *Lock) keepAlive(ctx context.Context, conn *bun.Conn) {
+	interval := l.ttl / 5
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+		checkCtx, cancel := context.WithTimeout(ctx, interval)
+		_, err := conn.ExecContext(checkCtx, "SELECT 1")
+		cancel()
+		if err != nil && ctx.Err() == nil {
+			log.Ctx(ctx).Warn().Err(err).Str("lockName", l.Name).Msg("lost connection of lock")
+			return // postgres releases the lock of the lost session
+		}
+	}
+}
+
+// Releases the lock and returns the connection to the pool. If the lock
+// can't be released, the connection is discarded, which releases the lock as
+// well.
+func (l *Lock) release(ctx context.Context, conn *bun.Conn) error {
+	defer conn.Close() // nolint: errcheck
+
+	var unlocked bool
+	err := conn.QueryRowContext(ctx, "SELECT pg_advisory_unlock(?)", l.key).Scan(&unlocked)
+	if err == nil && !unlocked {
+		err = errors.New("lock was not held")
+	}
+	if err != nil {
+		_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pace/bricks/backend/postgres"
+	"github.com/pace/bricks/maintenance/log"
+)
+
+func TestIntegration_PostgresLock(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+
+	ctx := log.WithContext(context.Background())
+	db := postgres.NewDB(ctx)
+
+	lock := NewLock(db, "test", 5*time.Second)
+	other := NewLock(db, "test", 5*time.Second)
+
+	ok, err := lock.Acquire(ctx)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// held by another session
+	ok, err = other.Acquire(ctx)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	require.NoError(t, lock.Release(ctx))
+
+	lockCtx, releaseLock, err := other.AcquireAndKeepUp(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, lockCtx)
+
+	ok, err = lock.Acquire(ctx)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	releaseLock()
+	<-lockCtx.Done()
+
+	waitCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	require.NoError(t, lock.AcquireWait(waitCtx))
+	require.NoError(t, lock.Release(ctx))
+}
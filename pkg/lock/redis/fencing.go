@@ -0,0 +1,210 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bsm/redislock"
+	"github.com/redis/go-redis/v9"
+)
+
+// Name of the pod or host holding a lock, as shown by Inspect.
+var holder = func() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return "unknown"
+	}
+	return hostname
+}()
+
+// Prefix of the counters of the fencing tokens. A counter is used per redis
+// cluster slot, so that the scripts only access keys of the slot of the lock
+// and no state has to be kept for released locks. The tokens of a lock are
+// increasing, as it always belongs to the same slot.
+const fencingKeyPrefix = "lock:fencing:"
+
+// Number of slots of a redis cluster
+const clusterSlots = 16384
+
+// Hash tags that map to the slots, found on first use
+var slotTags sync.Map // slot -> string
+
+// Issues the next fencing token and stores the metadata of the lock, if the
+// lock is still held with the given value. The metadata expires along with
+// the lock.
+var luaFence = redis.NewScript(`if redis.call("get", KEYS[1]) ~= ARGV[1] then return false end
+local token = redis.call("incr", KEYS[3])
+redis.call("hset", KEYS[2], "value", ARGV[1], "holder", ARGV[2], "acquired_at", ARGV[3], "token", token)
+redis.call("pexpire", KEYS[2], redis.call("pttl", KEYS[1]))
+return token`)
+
+// Returns the remaining ttl of the lock and its metadata, if the metadata
+// belongs to the current holder of the lock.
+var luaInspect = redis.NewScript(`local value = redis.call("get", KEYS[1])
+if not value then return false end
+local pttl = redis.call("pttl", KEYS[1])
+local meta = redis.call("hmget", KEYS[2], "value", "holder", "acquired_at", "token")
+if meta[1] ~= value then return {pttl} end
+return {pttl, meta[2], meta[3], meta[4]}`)
+
+type fencingTokenKey struct{}
+
+// FencingTokenFromContext returns the fencing token of the lock of a context
+// returned by AcquireAndKeepUp, see Lock.FencingToken.
+func FencingTokenFromContext(ctx context.Context) (int64, bool) {
+	token, ok := ctx.Value(fencingTokenKey{}).(int64)
+	return token, ok
+}
+
+// Info is the metadata of a held lock.
+type Info struct {
+	// Holder is the name of the pod or host holding the lock.
+	Holder string
+	// AcquiredAt is the time the lock was acquired.
+	AcquiredAt time.Time
+	// FencingToken is the fencing token issued to the holder.
+	FencingToken int64
+	// TTL is the remaining time to live of the lock, unless it is kept up.
+	TTL time.Duration
+}
+
+// Inspect returns the metadata of the lock, no matter who holds it, or nil if
+// the lock is not held. Only the TTL is set for locks held without metadata,
+// e.g. by an older version.
+func (l *Lock) Inspect(ctx context.Context) (*Info, error) {
+	r, err := luaInspect.Run(ctx, l.client, []string{l.Name, l.metaKey()}).Slice()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect lock %q: %w", l.Name, err)
+	}
+
+	info := &Info{}
+	if pttl, ok := r[0].(int64); ok && pttl > 0 {
+		info.TTL = time.Duration(pttl) * time.Millisecond
+	}
+	if len(r) < 4 {
+		return info, nil
+	}
+	info.Holder, _ = r[1].(string)
+	if acquiredAt, ok := r[2].(string); ok {
+		if ms, err := strconv.ParseInt(acquiredAt, 10, 64); err == nil {
+			info.AcquiredAt = time.UnixMilli(ms)
+		}
+	}
+	if token, ok := r[3].(string); ok {
+		info.FencingToken, _ = strconv.ParseInt(token, 10, 64)
+	}
+	return info, nil
+}
+
+// Obtains the lock and issues its fencing token. The token is issued only
+// while the lock is held, so a holder that loses the lock before it got its
+// token can't get a token higher than the one of the next holder.
+func (l *Lock) obtain(ctx context.Context, opts *redislock.Options) (*redislock.Lock, int64, error) {
+	start := time.Now()
+	lock, err := l.locker.Obtain(ctx, l.Name, l.lockTTL, opts)
+	if err != nil {
+		result := "error"
+		if errors.Is(err, redislock.ErrNotObtained) {
+			result = "contended"
+		}
+		paceLockAcquireDurationSeconds.WithLabelValues(result).Observe(time.Since(start).Seconds())
+		return nil, 0, err
+	}
+
+	token, err := luaFence.Run(ctx, l.client, []string{l.Name, l.metaKey(), fencingKey(l.Name)},
+		lock.Token()+lock.Metadata(), holder, time.Now().UnixMilli()).Int64()
+	if err != nil {
+		_ = lock.Release(context.WithoutCancel(ctx))
+		result := "error"
+		if errors.Is(err, redis.Nil) {
+			// the lock expired already
+			result, err = "contended", redislock.ErrNotObtained
+		}
+		paceLockAcquireDurationSeconds.WithLabelValues(result).Observe(time.Since(start).Seconds())
+		return nil, 0, err
+	}
+	paceLockAcquireDurationSeconds.WithLabelValues("acquired").Observe(time.Since(start).Seconds())
+	return lock, token, nil
+}
+
+// Extends the expiry of the metadata along with the lock.
+func (l *Lock) refreshMeta(ctx context.Context, ttl time.Duration) error {
+	return l.client.PExpire(ctx, l.metaKey(), ttl).Err()
+}
+
+// Returns the key of the metadata, it belongs to the slot of the lock.
+func (l *Lock) metaKey() string {
+	if _, ok := hashTag(l.Name); ok {
+		return l.Name + ":meta"
+	}
+	return "{" + l.Name + "}:meta"
+}
+
+// Returns the key of the counter of the fencing tokens of the slot of the
+// key.
+func fencingKey(key string) string {
+	slot := keySlot(key)
+	if tag, ok := slotTags.Load(slot); ok {
+		return fencingKeyPrefix + "{" + tag.(string) + "}"
+	}
+	// the numbers hash to all slots, on average a slot is hit every 16384
+	// numbers
+	var tag string
+	for i := 0; ; i++ {
+		tag = strconv.Itoa(i)
+		if crc16(tag)%clusterSlots == slot {
+			break
+		}
+	}
+	slotTags.Store(slot, tag)
+	return fencingKeyPrefix + "{" + tag + "}"
+}
+
+// Returns the hash tag of the key, the part between the first "{" and the
+// next "}", if it is not empty.
+func hashTag(key string) (string, bool) {
+	start := strings.IndexByte(key, '{')
+	if start < 0 {
+		return "", false
+	}
+	end := strings.IndexByte(key[start+1:], '}')
+	if end <= 0 {
+		return "", false
+	}
+	return key[start+1 : start+1+end], true
+}
+
+// Returns the redis cluster slot of the key.
+func keySlot(key string) uint16 {
+	if tag, ok := hashTag(key); ok {
+		key = tag
+	}
+	return crc16(key) % clusterSlots
+}
+
+// Returns the CRC16 (XMODEM) of s, as used by redis cluster.
+func crc16(s string) uint16 {
+	var crc uint16
+	for i := 0; i < len(s); i++ {
+		crc ^= uint16(s[i]) << 8
+		for range 8 {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
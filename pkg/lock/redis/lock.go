@@ -7,11 +7,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"sync"
 	"time"
 
 	redisbackend "github.com/pace/bricks/backend/redis"
 	pberrors "github.com/pace/bricks/maintenance/errors"
+	"github.com/pace/bricks/pkg/lock"
 
 	"github.com/bsm/redislock"
 	"github.com/redis/go-redis/v9"
@@ -25,34 +27,39 @@ var (
 )
 
 var (
-	ErrCouldNotLock    = errors.New("lock could not be obtained")
-	ErrCouldNotRelease = errors.New("lock could not be released")
+	ErrCouldNotLock    = lock.ErrCouldNotLock
+	ErrCouldNotRelease = lock.ErrCouldNotRelease
 )
 
+var _ lock.Lock = (*Lock)(nil)
+
 type Lock struct {
 	Name string
 
 	locker  *redislock.Client
+	client  *redis.Client // stores the fencing tokens and metadata
 	lockTTL time.Duration
 
 	lock  *redislock.Lock
+	token int64
 	mutex sync.Mutex
 }
 
 type LockOption func(l *Lock)
 
 func NewLock(name string, opts ...LockOption) *Lock {
-	initClient()
-	l := &Lock{Name: name}
-	for _, opt := range []LockOption{ // default options
-		SetTTL(5 * time.Second),
-		SetClient(getDefaultLocker()),
-	} {
-		opt(l)
-	}
+	l := &Lock{Name: name, lockTTL: 5 * time.Second}
 	for _, opt := range opts {
 		opt(l)
 	}
+	// the default redis client is only created if needed
+	if l.locker == nil {
+		l.locker = getDefaultLocker()
+	}
+	if l.client == nil {
+		initClient()
+		l.client = redisClient
+	}
 	return l
 }
 
@@ -64,7 +71,7 @@ func (l *Lock) Acquire(ctx context.Context) (bool, error) {
 		RetryStrategy: redislock.NoRetry(),
 	}
 
-	lock, err := l.locker.Obtain(ctx, l.Name, l.lockTTL, opts)
+	lock, token, err := l.obtain(ctx, opts)
 	if err != nil {
 		log.Ctx(ctx).Debug().Err(err).Str("lockName", l.Name).Msg("Could not acquire lock")
 		switch {
@@ -75,7 +82,7 @@ func (l *Lock) Acquire(ctx context.Context) (bool, error) {
 		}
 	}
 
-	l.lock = lock
+	l.lock, l.token = lock, token
 	return true, nil
 }
 
@@ -87,25 +94,26 @@ func (l *Lock) AcquireWait(ctx context.Context) error {
 		RetryStrategy: redislock.LinearBackoff(1 * time.Second),
 	}
 
-	lock, err := l.locker.Obtain(ctx, l.Name, l.lockTTL, opts)
+	lock, token, err := l.obtain(ctx, opts)
 	if err != nil {
 		log.Ctx(ctx).Debug().Err(err).Str("lockName", l.Name).Msg("Could not acquire lock")
 		return pberrors.Hide(ctx, err, ErrCouldNotLock)
 	}
 
-	l.lock = lock
+	l.lock, l.token = lock, token
 	return nil
 }
 
 // AcquireAndKeepUp will acquire a lock, and keep it up constantly until cancel is called,
 // the returned context is a lock context and is detached from the parent context, meaning that
 // any cancellation/timeout on the parent context does not affect this lock context.
+// The fencing token of the lock is available via FencingTokenFromContext.
 func (l *Lock) AcquireAndKeepUp(ctx context.Context) (context.Context, context.CancelFunc, error) {
 	opts := &redislock.Options{
 		RetryStrategy: redislock.NoRetry(),
 	}
 
-	lock, err := l.locker.Obtain(ctx, l.Name, l.lockTTL, opts)
+	lock, token, err := l.obtain(ctx, opts)
 	if err != nil {
 		switch {
 		case errors.Is(err, redislock.ErrNotObtained):
@@ -116,12 +124,16 @@ func (l *Lock) AcquireAndKeepUp(ctx context.Context) (context.Context, context.C
 	}
 
 	// Keep up lock, cancel lockCtx otherwise.
-	lockCtx, cancelLock := context.WithCancel(ctx)
+	lockCtx, cancelLock := context.WithCancel(context.WithValue(ctx, fencingTokenKey{}, token))
 	go func() {
 		defer pberrors.HandleWithCtx(ctx, fmt.Sprintf("keep up lock %q", l.Name)) // handle panics
 		defer cancelLock()
 
-		keepUpLock(lockCtx, lock, l.lockTTL)
+		l.keepUpLock(lockCtx, lock, l.lockTTL)
+		if lockCtx.Err() == nil {
+			paceLockLostTotal.Inc()
+			log.Ctx(ctx).Warn().Str("lockName", l.Name).Int64("fencingToken", token).Msg("lost lock")
+		}
 		err := lock.Release(ctx)
 		if err != nil && err != redislock.ErrLockNotHeld {
 			log.Ctx(lockCtx).Debug().Err(err).Msgf("could not release lock %q", l.Name)
@@ -133,7 +145,7 @@ func (l *Lock) AcquireAndKeepUp(ctx context.Context) (context.Context, context.C
 
 // Try to keep up a lock for as long as the context is valid. Return once the
 // lock is lost or the context is done.
-func keepUpLock(ctx context.Context, lock *redislock.Lock, refreshTTL time.Duration) {
+func (l *Lock) keepUpLock(ctx context.Context, lock *redislock.Lock, refreshTTL time.Duration) {
 	refreshInterval := refreshTTL / 5
 	lockRunsOutIn := refreshTTL // initial value after obtaining the lock
 	for {
@@ -163,6 +175,9 @@ func keepUpLock(ctx context.Context, lock *redislock.Lock, refreshTTL time.Durat
 		}
 		// reset, because the lock was refreshed
 		lockRunsOutIn = refreshTTL
+		if err := l.refreshMeta(ctx, refreshTTL); err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msg("could not refresh lock metadata")
+		}
 	}
 }
 
@@ -185,10 +200,22 @@ func (l *Lock) Release(ctx context.Context) error {
 		}
 	}
 
-	l.lock = nil
+	l.lock, l.token = nil, 0
 	return nil
 }
 
+// FencingToken returns the fencing token of the lock acquired by Acquire or
+// AcquireWait, or zero if the lock is not held. The tokens of a lock increase
+// monotonically with every acquisition, so that storage systems can reject
+// writes with a token lower than the highest token they have seen. This
+// protects against writes of holders that lost the lock, e.g. because they
+// paused for longer than the TTL.
+func (l *Lock) FencingToken() int64 {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.token
+}
+
 func initClient() {
 	initOnce.Do(func() {
 		redisClient = redisbackend.Client()
@@ -207,8 +234,37 @@ func SetTTL(ttl time.Duration) LockOption {
 	}
 }
 
+// SetClient sets the client used for locking. The fencing tokens and metadata
+// are stored using the default redis client, use SetRedisClient instead if the
+// client uses a different redis.
 func SetClient(client *redislock.Client) LockOption {
 	return func(l *Lock) {
 		l.locker = client
 	}
 }
+
+// SetRedisClient sets the redis client used for locking and for storing the
+// fencing tokens and metadata.
+func SetRedisClient(client *redis.Client) LockOption {
+	return func(l *Lock) {
+		l.locker = redislock.New(client)
+		l.client = client
+	}
+}
+
+// Locker creates redis locks, see lock.Locker.
+type Locker struct {
+	opts []LockOption
+}
+
+var _ lock.Locker = (*Locker)(nil)
+
+// NewLocker returns a locker creating locks with the options.
+func NewLocker(opts ...LockOption) *Locker {
+	return &Locker{opts: opts}
+}
+
+// NewLock returns the lock with the name and ttl.
+func (l *Locker) NewLock(name string, ttl time.Duration) lock.Lock {
+	return NewLock(name, slices.Concat(l.opts, []LockOption{SetTTL(ttl)})...)
+}
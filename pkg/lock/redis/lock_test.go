@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/require"
 )
 
@@ -40,3 +41,63 @@ func TestIntegration_RedisLock(t *testing.T) {
 		break
 	}
 }
+
+func TestIntegration_RedisLockFencing(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+
+	ctx := context.Background()
+	lock := NewLock("test:fencing", SetTTL(time.Minute))
+
+	info, err := lock.Inspect(ctx)
+	require.NoError(t, err)
+	require.Nil(t, info)
+
+	ok, err := lock.Acquire(ctx)
+	require.NoError(t, err)
+	require.True(t, ok)
+	first := lock.FencingToken()
+	require.Positive(t, first)
+
+	info, err = lock.Inspect(ctx)
+	require.NoError(t, err)
+	require.Equal(t, holder, info.Holder)
+	require.Equal(t, first, info.FencingToken)
+	require.WithinDuration(t, time.Now(), info.AcquiredAt, 5*time.Second)
+	require.Greater(t, info.TTL, 50*time.Second)
+
+	// other holders get higher tokens
+	ok, err = NewLock("test:fencing").Acquire(ctx)
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.NoError(t, lock.Release(ctx))
+	require.Zero(t, lock.FencingToken())
+
+	lockCtx, cancel, err := NewLock("test:fencing").AcquireAndKeepUp(ctx)
+	require.NoError(t, err)
+	defer cancel()
+	second, ok := FencingTokenFromContext(lockCtx)
+	require.True(t, ok)
+	require.Greater(t, second, first)
+}
+
+func TestSlots(t *testing.T) {
+	require.Equal(t, uint16(0x31c3), crc16("123456789"))
+	require.Equal(t, uint16(12182), keySlot("foo"))
+	require.Equal(t, keySlot("user1000"), keySlot("{user1000}.following"))
+	require.Equal(t, keySlot("foo{}{bar}"), crc16("foo{}{bar}")%clusterSlots)
+
+	for _, name := range []string{"test:fencing", "{tenant}:lock", "a{b}c"} {
+		l := &Lock{Name: name}
+		require.Equal(t, keySlot(name), keySlot(l.metaKey()), name)
+		require.Equal(t, keySlot(name), keySlot(fencingKey(name)), name)
+	}
+}
+
+func TestNewLock_redisClient(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: "localhost:0"})
+	l := NewLock("test", SetRedisClient(client))
+	require.Same(t, client, l.client)
+	require.NotNil(t, l.locker)
+}
@@ -0,0 +1,28 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package redis
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// The metrics are not labeled with the lock name, because lock names may
+// contain arbitrary keys.
+var (
+	paceLockAcquireDurationSeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "pace_lock_acquire_duration_seconds",
+			Help:    "Duration of attempts to acquire a lock, by result (acquired, contended or error).",
+			Buckets: []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+		},
+		[]string{"result"},
+	)
+	paceLockLostTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "pace_lock_lost_total",
+			Help: "A counter for locks kept up by AcquireAndKeepUp that were lost before they were released.",
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(paceLockAcquireDurationSeconds, paceLockLostTotal)
+}
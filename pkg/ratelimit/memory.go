@@ -0,0 +1,141 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+var _ Limiter = (*Memory)(nil)
+
+// Memory is the limiter that counts requests in memory, so the limit applies
+// per process. It is safe for concurrent use.
+type Memory struct {
+	limit Limit
+	now   func() time.Time
+
+	mx        sync.Mutex
+	states    map[string]*memoryState
+	lastSweep time.Time
+}
+
+type memoryState struct {
+	// token bucket
+	tokens float64
+	ts     time.Time
+
+	// sliding window
+	window    int64
+	cur, prev int
+
+	// the state can be dropped after this time, as it equals a new state
+	expiresAt time.Time
+}
+
+// InMemory returns a new limiter that counts requests in memory. It panics if
+// the limit is invalid.
+func InMemory(limit Limit) *Memory {
+	limit.validate()
+	return &Memory{
+		limit:  limit,
+		now:    time.Now,
+		states: make(map[string]*memoryState),
+	}
+}
+
+// Allow counts a request with the key against the limit and reports whether
+// it is allowed. It never returns an error.
+func (l *Memory) Allow(_ context.Context, key string) (Result, error) {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	s, ok := l.states[key]
+	if !ok {
+		s = &memoryState{tokens: float64(l.limit.capacity()), ts: now}
+		l.states[key] = s
+	}
+	if l.limit.Algorithm == SlidingWindow {
+		return l.slidingWindow(s, now), nil
+	}
+	return l.tokenBucket(s, now), nil
+}
+
+func (l *Memory) tokenBucket(s *memoryState, now time.Time) Result {
+	capacity := float64(l.limit.capacity())
+	interval := float64(l.limit.Period) / float64(l.limit.Requests) // per token
+
+	s.tokens = math.Min(capacity, s.tokens+float64(now.Sub(s.ts).Nanoseconds())/interval)
+	s.ts = now
+
+	r := Result{Limit: l.limit.capacity()}
+	if s.tokens >= 1 {
+		s.tokens--
+		r.Allowed = true
+	} else {
+		r.RetryAfter = time.Duration(math.Ceil((1 - s.tokens) * interval))
+	}
+	r.Remaining = int(s.tokens)
+	r.Reset = time.Duration(math.Ceil((capacity - s.tokens) * interval))
+	s.expiresAt = now.Add(r.Reset)
+	return r
+}
+
+func (l *Memory) slidingWindow(s *memoryState, now time.Time) Result {
+	period := l.limit.Period.Nanoseconds()
+	window := now.UnixNano() / period
+	elapsed := now.UnixNano() - window*period
+
+	if s.window != window {
+		if s.window == window-1 {
+			s.prev = s.cur
+		} else {
+			s.prev = 0
+		}
+		s.cur = 0
+		s.window = window
+	}
+
+	limit := float64(l.limit.Requests)
+	count := float64(s.prev)*float64(period-elapsed)/float64(period) + float64(s.cur)
+
+	r := Result{
+		Limit:      l.limit.Requests,
+		Reset:      time.Duration(period - elapsed),
+		RetryAfter: time.Duration(period - elapsed),
+	}
+	if count+1 <= limit {
+		s.cur++
+		count++
+		r.Allowed = true
+		r.RetryAfter = 0
+	} else if s.prev > 0 && limit-float64(s.cur)-1 >= 0 {
+		// the weight of the previous window decreases until a request fits
+		r.RetryAfter = time.Duration(math.Ceil(float64(period)*(1-(limit-float64(s.cur)-1)/float64(s.prev)))) - time.Duration(elapsed)
+	} else if s.cur > 0 {
+		// the current window becomes the previous one, same as above
+		r.RetryAfter += time.Duration(math.Ceil(max(0, float64(period)*(1-(limit-1)/float64(s.cur)))))
+	}
+	r.Remaining = max(0, int(limit-count))
+	s.expiresAt = time.Unix(0, (window+2)*period)
+	return r
+}
+
+// Removes the expired states once per period. Must be called with the lock
+// held.
+func (l *Memory) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.limit.Period {
+		return
+	}
+	l.lastSweep = now
+	for key, s := range l.states {
+		if !now.Before(s.expiresAt) {
+			delete(l.states, key)
+		}
+	}
+}
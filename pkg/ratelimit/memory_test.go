@@ -0,0 +1,114 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time             { return c.t }
+func (c *fakeClock) add(d time.Duration)        { c.t = c.t.Add(d) }
+func newFakeClock() *fakeClock                  { return &fakeClock{t: time.Unix(1_000_000, 0)} }
+func withClock(l *Memory, c *fakeClock) *Memory { l.now = c.now; return l }
+
+func allowN(t *testing.T, l Limiter, key string, n int) (allowed int, last Result) {
+	t.Helper()
+	for range n {
+		r, err := l.Allow(context.Background(), key)
+		require.NoError(t, err)
+		if r.Allowed {
+			allowed++
+		}
+		last = r
+	}
+	return allowed, last
+}
+
+func TestMemoryTokenBucket(t *testing.T) {
+	clock := newFakeClock()
+	l := withClock(InMemory(Limit{Requests: 10, Period: time.Second, Burst: 5}), clock)
+
+	allowed, r := allowN(t, l, "a", 6)
+	require.Equal(t, 5, allowed)
+	require.False(t, r.Allowed)
+	require.Equal(t, 5, r.Limit)
+	require.Equal(t, 0, r.Remaining)
+	require.Equal(t, 100*time.Millisecond, r.RetryAfter)
+	require.Equal(t, 500*time.Millisecond, r.Reset)
+
+	// other keys are limited separately
+	allowed, _ = allowN(t, l, "b", 1)
+	require.Equal(t, 1, allowed)
+
+	// refills one token per 100ms
+	clock.add(250 * time.Millisecond)
+	allowed, r = allowN(t, l, "a", 3)
+	require.Equal(t, 2, allowed)
+	require.Equal(t, 50*time.Millisecond, r.RetryAfter)
+
+	// never more than the burst
+	clock.add(time.Hour)
+	allowed, _ = allowN(t, l, "a", 10)
+	require.Equal(t, 5, allowed)
+}
+
+func TestMemorySlidingWindow(t *testing.T) {
+	clock := newFakeClock()
+	l := withClock(InMemory(Limit{Algorithm: SlidingWindow, Requests: 10, Period: time.Second}), clock)
+
+	allowed, r := allowN(t, l, "a", 11)
+	require.Equal(t, 10, allowed)
+	require.False(t, r.Allowed)
+	require.Equal(t, 10, r.Limit)
+	require.Equal(t, 0, r.Remaining)
+	require.Equal(t, time.Second, r.Reset)
+	// the current window weighs 9 after 1100ms
+	require.Equal(t, 1100*time.Millisecond, r.RetryAfter)
+
+	// half of the previous window still counts
+	clock.add(1500 * time.Millisecond)
+	allowed, r = allowN(t, l, "a", 6)
+	require.Equal(t, 5, allowed)
+	require.Equal(t, 500*time.Millisecond, r.Reset)
+	// the previous window weighs 5 at 1500ms, 4 at 1600ms
+	require.Equal(t, 100*time.Millisecond, r.RetryAfter)
+
+	clock.add(100 * time.Millisecond)
+	allowed, _ = allowN(t, l, "a", 2)
+	require.Equal(t, 1, allowed)
+
+	// previous windows are forgotten
+	clock.add(2 * time.Second)
+	allowed, _ = allowN(t, l, "a", 11)
+	require.Equal(t, 10, allowed)
+}
+
+func TestMemorySweep(t *testing.T) {
+	clock := newFakeClock()
+	l := withClock(InMemory(PerSecond(2)), clock)
+
+	allowN(t, l, "a", 1)
+	allowN(t, l, "b", 2)
+	require.Len(t, l.states, 2)
+
+	// a is full again, b is not
+	clock.add(time.Second - time.Millisecond)
+	allowN(t, l, "c", 1)
+	require.Len(t, l.states, 3)
+
+	clock.add(2 * time.Second)
+	allowN(t, l, "c", 1)
+	require.Len(t, l.states, 1)
+}
+
+func TestInvalidLimit(t *testing.T) {
+	require.Panics(t, func() { InMemory(Limit{Period: time.Second}) })
+	require.Panics(t, func() { InMemory(Limit{Requests: 1}) })
+	require.Panics(t, func() { InMemory(Limit{Algorithm: 5, Requests: 1, Period: time.Second}) })
+}
@@ -0,0 +1,109 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package ratelimit
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pace/bricks/http/jsonapi/runtime"
+	"github.com/pace/bricks/http/oauth2"
+	"github.com/pace/bricks/maintenance/log"
+)
+
+// Headers of the responses of the Middleware, see
+// https://datatracker.ietf.org/doc/draft-ietf-httpapi-ratelimit-headers/
+const (
+	HeaderLimit      = "RateLimit-Limit"
+	HeaderRemaining  = "RateLimit-Remaining"
+	HeaderReset      = "RateLimit-Reset"
+	HeaderRetryAfter = "Retry-After"
+)
+
+// KeyFunc returns the key to limit the request by. Requests with an empty key
+// are not limited.
+type KeyFunc func(r *http.Request) string
+
+// DefaultKey limits requests by the user, or the client if the token has no
+// user, see oauth2.UserID and oauth2.ClientID. Requests without a token are
+// limited by the remote IP, see log.ProxyAwareRemote.
+func DefaultKey(r *http.Request) string {
+	ctx := r.Context()
+	if id, ok := oauth2.UserID(ctx); ok && id != "" {
+		return "user:" + id
+	}
+	if id, ok := oauth2.ClientID(ctx); ok && id != "" {
+		return "client:" + id
+	}
+	if ip := log.ProxyAwareRemote(r); ip != "" {
+		return "ip:" + ip
+	}
+	return ""
+}
+
+// MiddlewareOption configures the Middleware.
+type MiddlewareOption func(*middleware)
+
+// WithKeyFunc sets the function that returns the key to limit requests by, it
+// defaults to DefaultKey.
+func WithKeyFunc(key KeyFunc) MiddlewareOption {
+	return func(m *middleware) {
+		m.key = key
+	}
+}
+
+type middleware struct {
+	limiter Limiter
+	key     KeyFunc
+	next    http.Handler
+}
+
+// Middleware limits the requests using the limiter. The limit is reported in
+// the RateLimit-* headers of every response. Requests exceeding the limit are
+// answered with status 429 and a Retry-After header. If the limiter fails,
+// requests are not limited.
+//
+// The middleware needs to run after the oauth2 middleware to limit requests
+// by user or client.
+func Middleware(limiter Limiter, opts ...MiddlewareOption) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		m := &middleware{limiter: limiter, key: DefaultKey, next: next}
+		for _, opt := range opts {
+			opt(m)
+		}
+		return m
+	}
+}
+
+func (m *middleware) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := m.key(r)
+	if key == "" {
+		m.next.ServeHTTP(w, r)
+		return
+	}
+
+	res, err := m.limiter.Allow(r.Context(), key)
+	if err != nil {
+		log.Req(r).Warn().Err(err).Msg("failed to rate limit request")
+		m.next.ServeHTTP(w, r)
+		return
+	}
+
+	h := w.Header()
+	h.Set(HeaderLimit, strconv.Itoa(res.Limit))
+	h.Set(HeaderRemaining, strconv.Itoa(res.Remaining))
+	h.Set(HeaderReset, seconds(res.Reset))
+	if !res.Allowed {
+		h.Set(HeaderRetryAfter, seconds(res.RetryAfter))
+		runtime.WriteError(w, http.StatusTooManyRequests, ErrLimitExceeded)
+		return
+	}
+	m.next.ServeHTTP(w, r)
+}
+
+// Returns the duration in whole seconds, rounded up.
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,88 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type failingLimiter struct{}
+
+func (failingLimiter) Allow(context.Context, string) (Result, error) {
+	return Result{}, errors.New("failed")
+}
+
+func TestMiddleware(t *testing.T) {
+	clock := newFakeClock()
+	l := withClock(InMemory(Limit{Requests: 1, Period: 2 * time.Second, Burst: 2}), clock)
+	h := Middleware(l)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	serve := func(remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve("1.2.3.4:1234")
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	require.Equal(t, "2", rec.Header().Get(HeaderLimit))
+	require.Equal(t, "1", rec.Header().Get(HeaderRemaining))
+	require.Equal(t, "2", rec.Header().Get(HeaderReset))
+
+	rec = serve("1.2.3.4:1234")
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	require.Equal(t, "0", rec.Header().Get(HeaderRemaining))
+	require.Equal(t, "4", rec.Header().Get(HeaderReset))
+
+	rec = serve("1.2.3.4:1234")
+	require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	require.Equal(t, "2", rec.Header().Get(HeaderRetryAfter))
+	require.Contains(t, rec.Body.String(), ErrLimitExceeded.Error())
+
+	// other remote addresses are limited separately
+	rec = serve("5.6.7.8:1234")
+	require.Equal(t, http.StatusNoContent, rec.Code)
+}
+
+func TestMiddlewareKeyFunc(t *testing.T) {
+	l := InMemory(Limit{Requests: 1, Period: time.Hour})
+	h := Middleware(l, WithKeyFunc(func(r *http.Request) string {
+		return r.Header.Get("X-Tenant")
+	}))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	for i, c := range []struct {
+		tenant string
+		code   int
+	}{
+		{"a", http.StatusOK},
+		{"a", http.StatusTooManyRequests},
+		{"b", http.StatusOK},
+		{"", http.StatusOK}, // not limited
+		{"", http.StatusOK},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-Tenant", c.tenant)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		require.Equal(t, c.code, rec.Code, "request %d", i)
+	}
+}
+
+func TestMiddlewareFailingLimiter(t *testing.T) {
+	h := Middleware(failingLimiter{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Empty(t, rec.Header().Get(HeaderLimit))
+}
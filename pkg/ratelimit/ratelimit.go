@@ -0,0 +1,90 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+// Package ratelimit limits the rate of requests per key, either in redis to
+// share the limit across processes or in memory. Middleware limits incoming
+// HTTP requests, RoundTripper throttles outgoing ones.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrLimitExceeded is returned if a request is not allowed by the limit.
+var ErrLimitExceeded = errors.New("rate limit exceeded")
+
+// Algorithm decides how requests are counted against a limit.
+type Algorithm int
+
+const (
+	// TokenBucket refills a bucket of Burst tokens at the rate of Requests per
+	// Period, every request takes a token. It allows short bursts while
+	// enforcing the average rate.
+	TokenBucket Algorithm = iota
+	// SlidingWindow allows Requests in any Period. It weights the count of the
+	// previous fixed window by its overlap with the sliding window, so it is
+	// an approximation that only needs two counters per key.
+	SlidingWindow
+)
+
+// Limit is the rate of requests allowed per key.
+type Limit struct {
+	Algorithm Algorithm
+	// Requests is the number of requests allowed per Period.
+	Requests int
+	Period   time.Duration
+	// Burst is the capacity of the token bucket, it defaults to Requests. It
+	// is ignored by the sliding window.
+	Burst int
+}
+
+// PerSecond returns a token bucket limit of n requests per second.
+func PerSecond(n int) Limit {
+	return Limit{Requests: n, Period: time.Second}
+}
+
+// PerMinute returns a token bucket limit of n requests per minute.
+func PerMinute(n int) Limit {
+	return Limit{Requests: n, Period: time.Minute}
+}
+
+// capacity is the maximum number of requests allowed at once.
+func (l Limit) capacity() int {
+	if l.Algorithm == TokenBucket && l.Burst > 0 {
+		return l.Burst
+	}
+	return l.Requests
+}
+
+func (l Limit) validate() {
+	if l.Requests <= 0 || l.Period <= 0 || l.Burst < 0 {
+		panic(fmt.Errorf("ratelimit: invalid limit %+v", l))
+	}
+	if l.Algorithm != TokenBucket && l.Algorithm != SlidingWindow {
+		panic(fmt.Errorf("ratelimit: unknown algorithm %d", l.Algorithm))
+	}
+}
+
+// Result is the decision of a limiter about a request.
+type Result struct {
+	Allowed bool
+	// Limit is the maximum number of requests allowed at once.
+	Limit int
+	// Remaining is the number of requests allowed after this one.
+	Remaining int
+	// Reset is the duration until the limit is fully available again, or the
+	// current window ends for the sliding window.
+	Reset time.Duration
+	// RetryAfter is the duration after which the next request is allowed, it
+	// is zero if the request is allowed.
+	RetryAfter time.Duration
+}
+
+// Limiter limits the rate of requests per key. It is safe for concurrent use.
+type Limiter interface {
+	// Allow counts a request with the key against the limit and reports
+	// whether it is allowed.
+	Allow(ctx context.Context, key string) (Result, error)
+}
@@ -0,0 +1,155 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/pace/bricks/maintenance/log"
+)
+
+var _ Limiter = (*Redis)(nil)
+
+// Lua script for Redis that takes a token from the bucket stored in a hash.
+// The bucket is refilled by one token per interval in milliseconds, using the
+// clock of redis. It returns whether the request is allowed, the remaining
+// tokens, and the reset and retry durations in milliseconds.
+var redisTokenBucket = redis.NewScript(`
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local capacity = tonumber(ARGV[1])
+local interval = tonumber(ARGV[2])
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1]) or capacity
+local ts = tonumber(state[2]) or now
+tokens = math.min(capacity, tokens + math.max(0, now - ts) / interval)
+local allowed, retry = 0, math.ceil((1 - tokens) * interval)
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed, retry = 1, 0
+end
+local reset = math.ceil((capacity - tokens) * interval)
+redis.call('HSET', KEYS[1], 'tokens', tokens, 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.max(reset, 1))
+return {allowed, math.floor(tokens), reset, retry}`)
+
+// Lua script for Redis that counts a request in the current fixed window of
+// the period in milliseconds, if the count of the sliding window allows it.
+// The counts of the windows are stored in a hash. It returns the same values
+// as redisTokenBucket.
+var redisSlidingWindow = redis.NewScript(`
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local limit = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local window = math.floor(now / period)
+local elapsed = now - window * period
+local counts = redis.call('HMGET', KEYS[1], window, window - 1)
+local cur = tonumber(counts[1]) or 0
+local prev = tonumber(counts[2]) or 0
+local count = prev * (period - elapsed) / period + cur
+local allowed, retry = 0, period - elapsed
+if count + 1 <= limit then
+	count = count + 1
+	allowed, retry = 1, 0
+	redis.call('HINCRBY', KEYS[1], window, 1)
+	redis.call('HDEL', KEYS[1], window - 2)
+	redis.call('PEXPIRE', KEYS[1], 2 * period)
+elseif prev > 0 and limit - cur - 1 >= 0 then
+	retry = math.ceil(period * (1 - (limit - cur - 1) / prev)) - elapsed
+elseif cur > 0 then
+	retry = retry + math.ceil(math.max(0, period * (1 - (limit - 1) / cur)))
+end
+return {allowed, math.max(0, math.floor(limit - count)), period - elapsed, retry}`)
+
+// Redis is the limiter that counts requests in redis, so the limit applies
+// across all processes sharing the prefix. It is safe for concurrent use.
+type Redis struct {
+	client   *redis.Client
+	prefix   string
+	limit    Limit
+	fallback Limiter
+}
+
+// RedisOption configures a Redis limiter.
+type RedisOption func(*Redis)
+
+// WithFallback sets the limiter that is used if redis fails. It defaults to a
+// Memory limiter with the same limit, so the limit applies per process while
+// redis is unavailable. If it is nil, the errors of redis are returned.
+func WithFallback(fallback Limiter) RedisOption {
+	return func(l *Redis) {
+		l.fallback = fallback
+	}
+}
+
+// InRedis returns a new limiter that connects to redis using the given
+// client. The prefix is used for every key that is stored. It panics if the
+// limit is invalid or the period is shorter than a millisecond.
+func InRedis(client *redis.Client, prefix string, limit Limit, opts ...RedisOption) *Redis {
+	limit.validate()
+	if limit.Period < time.Millisecond {
+		panic(fmt.Errorf("ratelimit: period %v is shorter than a millisecond", limit.Period))
+	}
+	l := &Redis{
+		client:   client,
+		prefix:   prefix,
+		limit:    limit,
+		fallback: InMemory(limit),
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Allow counts a request with the key against the limit and reports whether
+// it is allowed.
+func (l *Redis) Allow(ctx context.Context, key string) (Result, error) {
+	r, err := l.allow(ctx, l.prefix+key)
+	if err != nil && l.fallback != nil {
+		log.Ctx(ctx).Warn().Err(err).Str("key", key).Msg("rate limit falls back, redis failed")
+		return l.fallback.Allow(ctx, key)
+	}
+	return r, err
+}
+
+func (l *Redis) allow(ctx context.Context, key string) (Result, error) {
+	period := float64(l.limit.Period.Milliseconds())
+	var (
+		v   any
+		err error
+	)
+	switch l.limit.Algorithm {
+	case SlidingWindow:
+		v, err = redisSlidingWindow.Run(ctx, l.client, []string{key}, l.limit.Requests, period).Result()
+	default:
+		interval := period / float64(l.limit.Requests)
+		v, err = redisTokenBucket.Run(ctx, l.client, []string{key}, l.limit.capacity(), interval).Result()
+	}
+	if err != nil {
+		return Result{}, fmt.Errorf("redis: %w", err)
+	}
+
+	values, ok := v.([]interface{})
+	if !ok || len(values) != 4 {
+		return Result{}, fmt.Errorf("redis returned unexpected value %v", v)
+	}
+	var ints [4]int64
+	for i, v := range values {
+		if ints[i], ok = v.(int64); !ok {
+			return Result{}, fmt.Errorf("redis returned unexpected type %T, expected %T", v, ints[i])
+		}
+	}
+	return Result{
+		Allowed:    ints[0] == 1,
+		Limit:      l.limit.capacity(),
+		Remaining:  int(ints[1]),
+		Reset:      time.Duration(ints[2]) * time.Millisecond,
+		RetryAfter: time.Duration(ints[3]) * time.Millisecond,
+	}, nil
+}
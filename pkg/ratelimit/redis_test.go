@@ -0,0 +1,55 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pace/bricks/backend/redis"
+)
+
+func TestIntegrationRedis(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	ctx := context.Background()
+	client := redis.Client()
+
+	for _, limit := range []Limit{
+		{Algorithm: TokenBucket, Requests: 5, Period: time.Second},
+		{Algorithm: SlidingWindow, Requests: 5, Period: time.Second},
+	} {
+		key := time.Now().String()
+		require.NoError(t, client.Del(ctx, "test:ratelimit:"+key).Err())
+		l := InRedis(client, "test:ratelimit:", limit, WithFallback(nil))
+
+		allowed, r := allowN(t, l, key, 6)
+		require.Equal(t, 5, allowed, "algorithm %d", limit.Algorithm)
+		require.False(t, r.Allowed)
+		require.Equal(t, 5, r.Limit)
+		require.Equal(t, 0, r.Remaining)
+		require.Greater(t, r.RetryAfter, time.Duration(0))
+		require.LessOrEqual(t, r.RetryAfter, time.Second)
+
+		time.Sleep(r.RetryAfter + 10*time.Millisecond)
+		allowed, _ = allowN(t, l, key, 1)
+		require.Equal(t, 1, allowed, "algorithm %d", limit.Algorithm)
+	}
+}
+
+func TestRedisFallback(t *testing.T) {
+	// nothing listens on the port
+	client := redis.CustomClient(&goredis.Options{Addr: "127.0.0.1:1", MaxRetries: -1})
+	l := InRedis(client, "test:ratelimit:", PerMinute(1))
+
+	allowed, _ := allowN(t, l, "a", 2)
+	require.Equal(t, 1, allowed)
+
+	_, err := InRedis(client, "test:ratelimit:", PerMinute(1), WithFallback(nil)).Allow(context.Background(), "a")
+	require.Error(t, err)
+}
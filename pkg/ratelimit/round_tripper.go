@@ -0,0 +1,69 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package ratelimit
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pace/bricks/http/transport"
+	"github.com/pace/bricks/maintenance/log"
+)
+
+var _ transport.ChainableRoundTripper = (*RoundTripper)(nil)
+
+// RoundTripper implements a chainable round tripper that throttles requests
+// per host using a limiter. Requests exceeding the limit wait until they are
+// allowed. If the deadline of the request context would pass while waiting,
+// ErrLimitExceeded is returned right away. If the limiter fails, requests are
+// not throttled.
+type RoundTripper struct {
+	limiter   Limiter
+	transport http.RoundTripper
+}
+
+// NewRoundTripper returns a round tripper that throttles requests using the
+// limiter.
+func NewRoundTripper(limiter Limiter) *RoundTripper {
+	return &RoundTripper{limiter: limiter}
+}
+
+// Transport returns the RoundTripper to make HTTP requests
+func (rt *RoundTripper) Transport() http.RoundTripper {
+	return rt.transport
+}
+
+// SetTransport sets the RoundTripper to make HTTP requests
+func (rt *RoundTripper) SetTransport(t http.RoundTripper) {
+	rt.transport = t
+}
+
+// RoundTrip executes a single HTTP transaction via Transport() once the
+// limit allows it
+func (rt *RoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	for {
+		res, err := rt.limiter.Allow(ctx, "host:"+req.URL.Host)
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Msg("failed to rate limit request")
+			break
+		}
+		if res.Allowed {
+			break
+		}
+
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(res.RetryAfter).After(deadline) {
+			return nil, fmt.Errorf("%w: host %q", ErrLimitExceeded, req.URL.Host)
+		}
+		timer := time.NewTimer(res.RetryAfter)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	return rt.transport.RoundTrip(req)
+}
@@ -0,0 +1,54 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/pace/bricks/http/transport"
+)
+
+type countingTransport struct{ requests int }
+
+func (t *countingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	t.requests++
+	return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+}
+
+func TestRoundTripper(t *testing.T) {
+	final := &countingTransport{}
+	client := &http.Client{
+		Transport: transport.Chain(NewRoundTripper(InMemory(Limit{Requests: 1, Period: 50 * time.Millisecond}))).Final(final),
+	}
+
+	start := time.Now()
+	for range 3 {
+		resp, err := client.Get("http://example.com/")
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+	}
+	require.Equal(t, 3, final.requests)
+	require.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+
+	// the deadline passes before the request is allowed
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/", nil).WithContext(ctx)
+	req.RequestURI = ""
+	_, err := client.Do(req)
+	require.True(t, errors.Is(err, ErrLimitExceeded), err)
+	require.Equal(t, 3, final.requests)
+
+	// other hosts are limited separately
+	resp, err := client.Get("http://example.org/")
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, 4, final.requests)
+}
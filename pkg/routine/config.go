@@ -10,6 +10,8 @@ import (
 
 type config struct {
 	RedisLockTTL time.Duration `env:"ROUTINE_REDIS_LOCK_TTL" envDefault:"5s"`
+	// Minimum duration to wait before a worker is restarted after it panicked
+	WorkerRestartBackoff time.Duration `env:"ROUTINE_WORKER_RESTART_BACKOFF" envDefault:"1s"`
 }
 
 var cfg config
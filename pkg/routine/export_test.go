@@ -0,0 +1,14 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package routine
+
+import (
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// SetRedisClient sets the client used to store the last runs of scheduled
+// routines.
+func SetRedisClient(c *goredis.Client) {
+	redisClientOnce.Do(func() {})
+	redisClient = c
+}
@@ -5,23 +5,54 @@ package routine
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/getsentry/sentry-go"
 	"github.com/pace/bricks/maintenance/errors"
-	"github.com/pace/bricks/pkg/lock/redis"
+	"github.com/pace/bricks/pkg/lock"
 
 	exponential "github.com/jpillora/backoff"
 )
 
+type routineThatKeepsRunningInstances struct {
+	Name      string
+	Routine   func(context.Context)
+	Instances int
+	Locker    lock.Locker
+}
+
+func (r *routineThatKeepsRunningInstances) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < r.Instances; i++ {
+		instance := &routineThatKeepsRunningOneInstance{
+			Name:     r.Name,
+			Routine:  r.Routine,
+			Instance: i,
+			Locker:   r.Locker,
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			instance.Run(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
 type routineThatKeepsRunningOneInstance struct {
 	Name    string
 	Routine func(context.Context)
+	// Instance is the index of the instance if multiple instances are kept
+	// running in the group. Each instance uses its own lock.
+	Instance int
+	Locker   lock.Locker
 
 	lockTTL       time.Duration
 	retryInterval time.Duration
 	backoff       combinedExponentialBackoff
 	num           int64
+	panicked      bool
 }
 
 func (r *routineThatKeepsRunningOneInstance) Run(ctx context.Context) {
@@ -37,14 +68,20 @@ func (r *routineThatKeepsRunningOneInstance) Run(ctx context.Context) {
 		"lock":    &exponential.Backoff{Min: r.retryInterval, Max: 10 * time.Minute},
 		"routine": &exponential.Backoff{Min: r.retryInterval, Max: 10 * time.Minute},
 	}
-
 	r.num = ctx.Value(ctxNumKey{}).(int64)
+
 	var tryAgainIn time.Duration // zero on first run
 	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-time.After(tryAgainIn):
+		if r.panicked {
+			if !waitForRestart(ctx, r.Name, tryAgainIn) {
+				return
+			}
+		} else {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(tryAgainIn):
+			}
 		}
 		// Make sure to cancel the singleRunCtx so that the lock is released
 		// after the routine returned.
@@ -58,7 +95,8 @@ func (r *routineThatKeepsRunningOneInstance) Run(ctx context.Context) {
 // until it returns. Return the backoff duration after which another single run
 // should be performed.
 func (r *routineThatKeepsRunningOneInstance) singleRun(ctx context.Context) time.Duration {
-	l := redis.NewLock("routine:lock:"+r.Name, redis.SetTTL(r.lockTTL))
+	r.panicked = false
+	l := r.Locker.NewLock(r.lockName(), r.lockTTL)
 	lockCtx, cancel, err := l.AcquireAndKeepUp(ctx)
 	if err != nil {
 		go errors.Handle(ctx, err) // report error to Sentry, non-blocking
@@ -66,20 +104,33 @@ func (r *routineThatKeepsRunningOneInstance) singleRun(ctx context.Context) time
 	}
 	if lockCtx != nil {
 		defer cancel()
-		routinePanicked := true
-		func() {
-			defer errors.HandleWithCtx(ctx, fmt.Sprintf("routine %d", r.num)) // handle panics
-
-			span := sentry.StartSpan(lockCtx, "function", sentry.WithDescription(fmt.Sprintf("routine %d", r.num)))
-			defer span.Finish()
-
-			r.Routine(span.Context())
-			routinePanicked = false
-		}()
-		if routinePanicked {
+		if r.panicked = runInstance(ctx, lockCtx, r.num, r.Routine); r.panicked {
 			return r.backoff.Duration("routine")
 		}
 	}
 	r.backoff.ResetAll()
 	return r.retryInterval
 }
+
+// The first instance uses the same lock as KeepRunningOneInstance, so that
+// KeepRunningInstances(1) is equivalent to it.
+func (r *routineThatKeepsRunningOneInstance) lockName() string {
+	if r.Instance == 0 {
+		return "routine:lock:" + r.Name
+	}
+	return fmt.Sprintf("routine:lock:%s:%d", r.Name, r.Instance)
+}
+
+// Runs a single instance of a routine until it returns. Panics are logged and
+// sent to sentry. Returns whether the routine panicked.
+func runInstance(ctx, routineCtx context.Context, num int64, routine func(context.Context)) (panicked bool) {
+	panicked = true
+	func() {
+		defer errors.HandleWithCtx(ctx, fmt.Sprintf("routine %d", num)) // handle panics
+		span := sentry.StartSpan(routineCtx, "function", sentry.WithDescription(fmt.Sprintf("routine %d", num)))
+		defer span.Finish()
+		routine(span.Context())
+		panicked = false
+	}()
+	return panicked
+}
@@ -0,0 +1,71 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package routine
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	paceRoutineRunning = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "pace_routine_running",
+			Help: "A gauge of instances of a named routine currently running in this process.",
+		},
+		[]string{"routine"},
+	)
+	paceRoutineRestarting = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "pace_routine_restarting",
+			Help: "A gauge of instances of a named routine waiting to be restarted after a panic.",
+		},
+		[]string{"routine"},
+	)
+	paceRoutinePanicTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "pace_routine_panic_total",
+			Help: "A counter for panics of instances of a named routine.",
+		},
+		[]string{"routine"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(paceRoutineRunning, paceRoutineRestarting, paceRoutinePanicTotal)
+}
+
+// Returns the routine instrumented with the metrics of the named routine. The
+// panic of the routine is passed on after it was counted.
+func instrumented(name string, routine func(context.Context)) func(context.Context) {
+	return func(ctx context.Context) {
+		paceRoutineRunning.WithLabelValues(name).Inc()
+		defer paceRoutineRunning.WithLabelValues(name).Dec()
+		defer func() {
+			if rp := recover(); rp != nil {
+				paceRoutinePanicTotal.WithLabelValues(name).Inc()
+				panic(rp)
+			}
+		}()
+		routine(ctx)
+	}
+}
+
+// Waits for the duration after an instance of the named routine panicked.
+// Returns false if the context is done before. Unnamed routines are not
+// counted.
+func waitForRestart(ctx context.Context, name string, d time.Duration) bool {
+	if name != "" {
+		paceRoutineRestarting.WithLabelValues(name).Inc()
+		defer paceRoutineRestarting.WithLabelValues(name).Dec()
+	}
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
@@ -11,14 +11,22 @@ import (
 	"sync"
 	"sync/atomic"
 	"syscall"
+	"time"
 
 	"github.com/getsentry/sentry-go"
 	"github.com/pace/bricks/maintenance/errors"
+	"github.com/pace/bricks/maintenance/lifecycle"
 	"github.com/pace/bricks/maintenance/log"
+	"github.com/pace/bricks/pkg/lock"
+	"github.com/pace/bricks/pkg/lock/redis"
+	"github.com/robfig/cron/v3"
 )
 
 type options struct {
-	keepRunningOneInstance bool
+	keepRunningInstances int
+	schedule             schedule
+	workers              int
+	locker               lock.Locker
 }
 
 // Option specifies how a routine is run.
@@ -26,12 +34,8 @@ type Option func(*options)
 
 // Ideas for options in the future:
 //  * Timeout/Deadline for the context
-//  * Cronjob("30 */2 * * *"): run according to the crontab notation
 //  * AutoRestart(time.Duration): restart after routine finishes, but at most
 //    once per duration
-//  * Every(time.Duration): run regularly, use redis to get consistent behaviour
-//    on process restarts
-//  * Workers(int): run the routine a number of times in parallel
 //  * Delay(time.Duration): run once after timeout
 //  * allow useful combinations of OnceSimultaneously and other options
 //  * join a group explicitly by selecting a different redis database
@@ -52,8 +56,84 @@ type Option func(*options)
 // Due to lack of a better name, the name of this option is quite verbose. Feel
 // free to propose any better name as an alias for this option.
 func KeepRunningOneInstance() Option {
+	return KeepRunningInstances(1)
+}
+
+// KeepRunningInstances returns an option that behaves like
+// KeepRunningOneInstance, except that the given number of instances of the
+// routine are kept running in the group simultaneously. The instances are not
+// necessarily distributed evenly across the callers. KeepRunningInstances
+// panics if n is not positive.
+func KeepRunningInstances(n int) Option {
+	if n <= 0 {
+		panic(fmt.Errorf("routine: invalid number of instances %d", n))
+	}
+	return func(o *options) {
+		o.keepRunningInstances = n
+	}
+}
+
+// Workers returns an option that runs the routine n times in parallel in the
+// calling process. A worker that panics is restarted with exponential backoff,
+// a worker that returns regularly is not restarted. The routine returns once
+// all workers returned. Workers panics if n is not positive.
+//
+// Workers can be combined with the other options, e.g. with
+// KeepRunningOneInstance to run all workers in one process of the group or
+// with Every to run the workers at every slot of the schedule. Workers is the
+// only option that can be passed to Run, the workers of unnamed routines are
+// not part of the metrics.
+func Workers(n int) Option {
+	if n <= 0 {
+		panic(fmt.Errorf("routine: invalid number of workers %d", n))
+	}
+	return func(o *options) {
+		o.workers = n
+	}
+}
+
+// Cronjob returns an option that runs the routine according to the crontab
+// notation, e.g. "30 */2 * * *". Besides the five standard fields, descriptors
+// like "@hourly" and a leading "CRON_TZ=Europe/Berlin" are supported. Cronjob
+// panics if the spec can't be parsed.
+//
+// In clusters with multiple processes the routine runs exactly once per slot
+// of the schedule in any one of the callers. The slot of the last run is
+// stored in redis, so the schedule survives restarts of the processes. If
+// slots were missed, because no caller was running, the routine runs once
+// immediately to catch up. A run never overlaps with the run of a previous
+// slot, the next slot is delayed instead.
+func Cronjob(spec string) Option {
+	s, err := cron.ParseStandard(spec)
+	if err != nil {
+		panic(fmt.Errorf("routine: invalid cronjob spec %q: %w", spec, err))
+	}
 	return func(o *options) {
-		o.keepRunningOneInstance = true
+		o.schedule = cronSchedule{s}
+	}
+}
+
+// Every returns an option that runs the routine regularly with the interval in
+// between the starts of two runs. If the routine never ran before, it runs
+// immediately. Every panics if the interval is not positive.
+//
+// Every has the same behaviour in clusters as Cronjob.
+func Every(interval time.Duration) Option {
+	if interval <= 0 {
+		panic(fmt.Errorf("routine: invalid interval %s", interval))
+	}
+	return func(o *options) {
+		o.schedule = intervalSchedule(interval)
+	}
+}
+
+// Locker returns an option that uses the locker for the locks of
+// KeepRunningOneInstance, KeepRunningInstances, Cronjob and Every, instead of
+// locks in the default redis database. All members of a group must use the
+// same locker. Cronjob and Every store the last run in redis nevertheless.
+func Locker(locker lock.Locker) Option {
+	return func(o *options) {
+		o.locker = locker
 	}
 }
 
@@ -64,28 +144,69 @@ func KeepRunningOneInstance() Option {
 // redis database are members of the same group, no matter whether they are
 // goroutines of a single process or of processes running on different hosts.
 // The default redis database is configured via the REDIS_* environment
-// variables.
+// variables. The schedule options Cronjob and Every take precedence over
+// KeepRunningOneInstance and KeepRunningInstances. Metrics of named routines
+// are labeled with the name.
 func RunNamed(parentCtx context.Context, name string, routine func(context.Context), opts ...Option) (cancel context.CancelFunc) {
 	o := options{}
 	for _, opt := range opts {
 		opt(&o)
 	}
+	if o.locker == nil {
+		o.locker = redis.NewLocker()
+	}
 
-	if o.keepRunningOneInstance {
-		routine = (&routineThatKeepsRunningOneInstance{
+	routine = instrumented(name, routine)
+	if o.workers > 0 {
+		routine = (&routineWithWorkers{
 			Name:    name,
 			Routine: routine,
+			Workers: o.workers,
+		}).Run
+	}
+	if o.schedule != nil {
+		routine = (&routineOnSchedule{
+			Name:     name,
+			Routine:  routine,
+			Schedule: o.schedule,
+			Locker:   o.locker,
+		}).Run
+	} else if o.keepRunningInstances > 0 {
+		routine = (&routineThatKeepsRunningInstances{
+			Name:      name,
+			Routine:   routine,
+			Instances: o.keepRunningInstances,
+			Locker:    o.locker,
 		}).Run
 	}
 
-	return Run(parentCtx, routine)
+	return run(parentCtx, routine)
 }
 
 // Run runs the given function in a new background context. Panics
 // thrown in the function are logged and sent to sentry. The routines context is
 // canceled if the program receives a shutdown signal (SIGINT, SIGTERM), if the
-// returned CancelFunc is called, or if the routine returned.
-func Run(ctx context.Context, routine func(context.Context)) (cancel context.CancelFunc) {
+// returned CancelFunc is called, or if the routine returned. The only option
+// supported is Workers, Run panics if any other option is passed, as they
+// require a name, see RunNamed.
+func Run(ctx context.Context, routine func(context.Context), opts ...Option) (cancel context.CancelFunc) {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.keepRunningInstances > 0 || o.schedule != nil || o.locker != nil {
+		panic(fmt.Errorf("routine: only the Workers option is supported by Run, use RunNamed"))
+	}
+	if o.workers > 0 {
+		routine = (&routineWithWorkers{
+			Routine: routine,
+			Workers: o.workers,
+		}).Run
+	}
+	return run(ctx, routine)
+}
+
+func run(ctx context.Context, routine func(context.Context)) (cancel context.CancelFunc) {
 	ctx = context.WithoutCancel(ctx)
 
 	// add routine number to context and logger
@@ -100,6 +221,7 @@ func Run(ctx context.Context, routine func(context.Context)) (cancel context.Can
 	// register context to be cancelled when the program is shut down
 	contextsMx.Lock()
 	contexts[num] = cancel
+	running.Add(1)
 	contextsMx.Unlock()
 	// deregister the above if context is done
 	go func() {
@@ -111,6 +233,7 @@ func Run(ctx context.Context, routine func(context.Context)) (cancel context.Can
 		delete(contexts, num)
 	}()
 	go func() {
+		defer running.Done()
 		defer errors.HandleWithCtx(ctx, fmt.Sprintf("routine %d", num)) // handle panics
 		defer cancel()
 
@@ -127,18 +250,52 @@ type ctxNumKey struct{}
 var (
 	contextsMx sync.Mutex
 	contexts   = map[int64]context.CancelFunc{}
+	running    sync.WaitGroup
 	ctr        int64
+
+	cancelAllOnce sync.Once
 )
 
 // Starts a go routine that cancels all contexts for routines created by Run if
 // we receive a SIGINT/SIGTERM. This allows those routines to gracefully handle
-// the shutdown.
+// the shutdown. If the shutdown is coordinated by package lifecycle, the
+// routines are canceled in its routines phase instead.
 func init() {
+	lifecycle.OnShutdown(lifecycle.PhaseRoutines, "routines", Shutdown)
+
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
 	go func() {
 		<-c // block until SIGINT/SIGTERM is received
 		signal.Stop(c)
+		if lifecycle.Coordinating() {
+			return
+		}
+		cancelAll("received shutdown signal, canceling all running routines")
+	}()
+}
+
+// Shutdown cancels the contexts of all routines created by Run and waits until
+// the routines returned or the context is done. Starting new routines blocks
+// once Shutdown was called.
+func Shutdown(ctx context.Context) error {
+	cancelAll("shutting down, canceling all running routines")
+
+	done := make(chan struct{})
+	go func() {
+		running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func cancelAll(msg string) {
+	cancelAllOnce.Do(func() {
 		// no unlock, to block creating new routines while the program exits
 		contextsMx.Lock()
 		// Cancel all contexts. For contexts that are already done this is a
@@ -146,11 +303,11 @@ func init() {
 		log.Logger().Info().
 			Int("count", len(contexts)).
 			Ints64("routines", routineNumbers()).
-			Msg("received shutdown signal, canceling all running routines")
+			Msg(msg)
 		for _, cancel := range contexts {
 			cancel()
 		}
-	}()
+	})
 }
 
 func routineNumbers() []int64 {
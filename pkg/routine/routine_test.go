@@ -7,16 +7,21 @@ import (
 	"context"
 	"os"
 	"os/exec"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"testing"
 	"time"
 
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/require"
 
 	"github.com/pace/bricks/http/security"
 	"github.com/pace/bricks/locale"
 	"github.com/pace/bricks/maintenance/log"
+	"github.com/pace/bricks/pkg/lock"
 	"github.com/pace/bricks/pkg/routine"
 )
 
@@ -203,3 +208,203 @@ type token string
 func (t token) GetValue() string {
 	return string(t)
 }
+
+func TestRunNamed_workers(t *testing.T) {
+	var mx sync.Mutex
+	runs := 0
+	var wg sync.WaitGroup
+	wg.Add(3)
+	routine.RunNamed(context.Background(), "workers", func(ctx context.Context) {
+		mx.Lock()
+		defer mx.Unlock()
+		runs++
+		wg.Done()
+	}, routine.Workers(3))
+	wg.Wait()
+	require.Equal(t, 3, runs)
+}
+
+func TestRun_workers(t *testing.T) {
+	var mx sync.Mutex
+	runs := 0
+	var wg sync.WaitGroup
+	wg.Add(3)
+	routine.Run(context.Background(), func(ctx context.Context) {
+		mx.Lock()
+		defer mx.Unlock()
+		runs++
+		wg.Done()
+	}, routine.Workers(3))
+	wg.Wait()
+	require.Equal(t, 3, runs)
+}
+
+func TestRun_unsupportedOption(t *testing.T) {
+	require.Panics(t, func() {
+		routine.Run(context.Background(), func(ctx context.Context) {}, routine.KeepRunningOneInstance())
+	})
+}
+
+func TestRunNamed_workersAreRestartedAfterPanic(t *testing.T) {
+	done := make(chan struct{})
+	var mx sync.Mutex
+	calls := 0
+	routine.RunNamed(context.Background(), "panicking-worker", func(ctx context.Context) {
+		mx.Lock()
+		calls++
+		first := calls == 1
+		mx.Unlock()
+		if first {
+			panic("test")
+		}
+		close(done)
+	}, routine.Workers(1))
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("worker was not restarted after panic")
+	}
+}
+
+// memLocker holds locks in memory, it is shared by the callers of a test like
+// a redis database by processes.
+type memLocker struct {
+	mx   sync.Mutex
+	held map[string]bool
+}
+
+func newMemLocker() *memLocker {
+	return &memLocker{held: make(map[string]bool)}
+}
+
+func (l *memLocker) NewLock(name string, _ time.Duration) lock.Lock {
+	return &memLock{locker: l, name: name}
+}
+
+type memLock struct {
+	lock.Lock
+	locker *memLocker
+	name   string
+}
+
+func (l *memLock) AcquireAndKeepUp(ctx context.Context) (context.Context, context.CancelFunc, error) {
+	l.locker.mx.Lock()
+	defer l.locker.mx.Unlock()
+	if l.locker.held[l.name] {
+		return nil, nil, nil
+	}
+	l.locker.held[l.name] = true
+	lockCtx, cancel := context.WithCancel(ctx)
+	context.AfterFunc(lockCtx, func() {
+		l.locker.mx.Lock()
+		defer l.locker.mx.Unlock()
+		delete(l.locker.held, l.name)
+	})
+	return lockCtx, cancel, nil
+}
+
+func TestRunNamed_keepRunningInstances(t *testing.T) {
+	locker := newMemLocker()
+	var running, starts atomic.Int32
+	instance := func(ctx context.Context) {
+		starts.Add(1)
+		running.Add(1)
+		defer running.Add(-1)
+		<-ctx.Done()
+	}
+	opts := []routine.Option{routine.KeepRunningInstances(2), routine.Locker(locker)}
+
+	cancelA := routine.RunNamed(context.Background(), "instances", instance, opts...)
+	defer cancelA()
+	require.Eventually(t, func() bool { return running.Load() == 2 }, time.Second, time.Millisecond)
+
+	// the second caller doesn't run more instances
+	cancelB := routine.RunNamed(context.Background(), "instances", instance, opts...)
+	defer cancelB()
+	require.Never(t, func() bool { return running.Load() > 2 }, 200*time.Millisecond, time.Millisecond)
+
+	// the stopped instances are replaced by the second caller
+	cancelA()
+	require.Eventually(t, func() bool {
+		return starts.Load() == 4 && running.Load() == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Never(t, func() bool { return running.Load() > 2 }, 200*time.Millisecond, time.Millisecond)
+}
+
+// Returns a redis server for the last runs of scheduled routines.
+func scheduleRedis(t *testing.T) *miniredis.Miniredis {
+	mr := miniredis.RunT(t)
+	routine.SetRedisClient(goredis.NewClient(&goredis.Options{Addr: mr.Addr()}))
+	return mr
+}
+
+func TestRunNamed_every(t *testing.T) {
+	mr := scheduleRedis(t)
+	locker := newMemLocker()
+
+	var (
+		mx      sync.Mutex
+		slots   []string
+		running atomic.Int32
+		overlap atomic.Bool
+	)
+	run := func(ctx context.Context) {
+		if running.Add(1) > 1 {
+			overlap.Store(true)
+		}
+		defer running.Add(-1)
+		// the slot of the run is claimed while the lock is held
+		slot, _ := mr.Get("routine:lastrun:every")
+		mx.Lock()
+		slots = append(slots, slot)
+		mx.Unlock()
+		// runs last longer than the interval, missed slots are caught up
+		time.Sleep(150 * time.Millisecond)
+	}
+	opts := []routine.Option{routine.Every(100 * time.Millisecond), routine.Locker(locker)}
+
+	start := time.Now()
+	cancelA := routine.RunNamed(context.Background(), "every", run, opts...)
+	cancelB := routine.RunNamed(context.Background(), "every", run, opts...)
+	time.Sleep(time.Second)
+	cancelA()
+	cancelB()
+	elapsed := time.Since(start)
+
+	require.False(t, overlap.Load(), "runs overlapped")
+	mx.Lock()
+	defer mx.Unlock()
+	require.GreaterOrEqual(t, len(slots), 3)
+	require.LessOrEqual(t, len(slots), int(elapsed/(100*time.Millisecond))+1)
+	seen := map[string]bool{}
+	for _, slot := range slots {
+		require.NotEmpty(t, slot)
+		require.False(t, seen[slot], "slot %s ran twice", slot)
+		seen[slot] = true
+	}
+}
+
+func TestRunNamed_cronjobCatchesUpOnce(t *testing.T) {
+	mr := scheduleRedis(t)
+	locker := newMemLocker()
+
+	// the last run was hours ago
+	now := time.Now().UTC()
+	last := now.Truncate(time.Hour).Add(-10 * time.Hour)
+	require.NoError(t, mr.Set("routine:lastrun:cronjob", strconv.FormatInt(last.UnixMilli(), 10)))
+
+	var runs atomic.Int32
+	run := func(ctx context.Context) { runs.Add(1) }
+	opts := []routine.Option{routine.Cronjob("CRON_TZ=UTC 0 * * * *"), routine.Locker(locker)}
+	cancelA := routine.RunNamed(context.Background(), "cronjob", run, opts...)
+	defer cancelA()
+	cancelB := routine.RunNamed(context.Background(), "cronjob", run, opts...)
+	defer cancelB()
+
+	// the missed slots are caught up by a single run for the latest one
+	require.Eventually(t, func() bool { return runs.Load() == 1 }, time.Second, time.Millisecond)
+	require.Never(t, func() bool { return runs.Load() > 1 }, 300*time.Millisecond, 10*time.Millisecond)
+	lastRun, err := mr.Get("routine:lastrun:cronjob")
+	require.NoError(t, err)
+	require.Equal(t, strconv.FormatInt(now.Truncate(time.Hour).UnixMilli(), 10), lastRun)
+}
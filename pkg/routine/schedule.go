@@ -0,0 +1,207 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package routine
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	exponential "github.com/jpillora/backoff"
+	"github.com/robfig/cron/v3"
+
+	redisbackend "github.com/pace/bricks/backend/redis"
+	"github.com/pace/bricks/maintenance/errors"
+	"github.com/pace/bricks/pkg/lock"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// schedule determines the slots in which a scheduled routine runs.
+type schedule interface {
+	// slot returns the slot in which the routine should run next, given the
+	// slot of the last run. If there was no last run, last is the zero time.
+	// The returned slot is in the past if slots were missed, e.g. because no
+	// member of the group was running. In that case the latest missed slot is
+	// returned, so that missed runs are caught up exactly once.
+	slot(last, now time.Time) time.Time
+}
+
+type cronSchedule struct {
+	cron.Schedule
+}
+
+func (s cronSchedule) slot(last, now time.Time) time.Time {
+	if last.IsZero() {
+		return s.Next(now)
+	}
+	slot := s.Next(last)
+	if slot.After(now) {
+		return slot
+	}
+	for {
+		next := s.Next(slot)
+		if next.After(now) {
+			return slot
+		}
+		slot = next
+	}
+}
+
+type intervalSchedule time.Duration
+
+func (s intervalSchedule) slot(last, now time.Time) time.Time {
+	interval := time.Duration(s)
+	if last.IsZero() {
+		return now.Truncate(time.Millisecond)
+	}
+	if slot := last.Add(interval); slot.After(now) {
+		return slot
+	}
+	return last.Add(now.Sub(last) / interval * interval)
+}
+
+type routineOnSchedule struct {
+	Name     string
+	Routine  func(context.Context)
+	Schedule schedule
+	Locker   lock.Locker
+
+	lockTTL       time.Duration
+	retryInterval time.Duration
+	backoff       combinedExponentialBackoff
+	num           int64
+}
+
+func (r *routineOnSchedule) Run(ctx context.Context) {
+	// The retry interval is used if we did not get the lock because the
+	// routine is still running in some other member of the group. The
+	// exponential backoff is used if we encounter problems with Redis.
+	r.lockTTL = cfg.RedisLockTTL
+	r.retryInterval = r.lockTTL / 5
+	r.backoff = combinedExponentialBackoff{
+		"redis": &exponential.Backoff{Min: r.retryInterval, Max: 10 * time.Minute},
+	}
+	r.num = ctx.Value(ctxNumKey{}).(int64)
+
+	var tryAgainIn time.Duration // zero on first run
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(tryAgainIn):
+		}
+		tryAgainIn = r.singleRun(ctx)
+	}
+}
+
+// Performs a single run. That is, to determine the next slot and, if it is
+// due, to claim the slot and run the routine. Returns the duration after which
+// another single run should be performed.
+func (r *routineOnSchedule) singleRun(ctx context.Context) time.Duration {
+	last, err := loadLastRun(ctx, r.Name)
+	if err != nil {
+		go errors.Handle(ctx, err) // report error to Sentry, non-blocking
+		return r.backoff.Duration("redis")
+	}
+	slot := r.Schedule.slot(last, time.Now())
+	if wait := time.Until(slot); wait > 0 {
+		// Load the last run again once the slot is due, it may have been
+		// claimed by another member of the group in the meantime.
+		return wait
+	}
+
+	// Make sure to cancel the singleRunCtx so that the lock is released
+	// after the routine returned.
+	singleRunCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	l := r.Locker.NewLock("routine:lock:"+r.Name, r.lockTTL)
+	lockCtx, cancelLock, err := l.AcquireAndKeepUp(singleRunCtx)
+	if err != nil {
+		go errors.Handle(ctx, err) // report error to Sentry, non-blocking
+		return r.backoff.Duration("redis")
+	}
+	if lockCtx == nil {
+		// The routine of a previous slot is still running.
+		return r.retryInterval
+	}
+	defer cancelLock()
+
+	claimed, err := claimSlot(lockCtx, r.Name, last, slot)
+	if err != nil {
+		go errors.Handle(ctx, err) // report error to Sentry, non-blocking
+		return r.backoff.Duration("redis")
+	}
+	r.backoff.ResetAll()
+	if !claimed {
+		// Another member of the group already ran the routine for this slot.
+		return 0
+	}
+
+	runInstance(ctx, lockCtx, r.num, r.Routine)
+	return 0
+}
+
+var (
+	redisClientOnce sync.Once
+	redisClient     *goredis.Client
+)
+
+func getRedisClient() *goredis.Client {
+	redisClientOnce.Do(func() {
+		redisClient = redisbackend.Client()
+	})
+	return redisClient
+}
+
+func lastRunKey(name string) string {
+	return "routine:lastrun:" + name
+}
+
+// Returns the slot of the last run of the named routine, or the zero time if
+// the routine never ran.
+func loadLastRun(ctx context.Context, name string) (time.Time, error) {
+	v, err := getRedisClient().Get(ctx, lastRunKey(name)).Result()
+	if err == goredis.Nil {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("loading last run of routine %q: %w", name, err)
+	}
+	ms, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parsing last run of routine %q: %w", name, err)
+	}
+	return time.UnixMilli(ms), nil
+}
+
+// Lua script for Redis that sets a key to ARGV[2] only if its current value is
+// ARGV[1]. An empty ARGV[1] matches a key that does not exist.
+var redisCompareAndSet = goredis.NewScript(`
+local current = redis.call('get', KEYS[1])
+if (current == false and ARGV[1] == '') or current == ARGV[1] then
+	redis.call('set', KEYS[1], ARGV[2])
+	return 1
+end
+return 0`)
+
+// Claims the slot for the named routine. Returns true if the slot was claimed
+// by the caller, false if another member of the group claimed a slot since
+// last was loaded.
+func claimSlot(ctx context.Context, name string, last, slot time.Time) (bool, error) {
+	var expected string
+	if !last.IsZero() {
+		expected = strconv.FormatInt(last.UnixMilli(), 10)
+	}
+	claimed, err := redisCompareAndSet.Run(ctx, getRedisClient(),
+		[]string{lastRunKey(name)},
+		expected, strconv.FormatInt(slot.UnixMilli(), 10),
+	).Int()
+	if err != nil {
+		return false, fmt.Errorf("claiming slot of routine %q: %w", name, err)
+	}
+	return claimed == 1, nil
+}
@@ -0,0 +1,48 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package routine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCronSchedule_slot(t *testing.T) {
+	s, err := cron.ParseStandard("30 */2 * * *")
+	require.NoError(t, err)
+	sched := cronSchedule{s}
+	now := time.Date(2026, 1, 1, 5, 0, 0, 0, time.UTC)
+
+	t.Run("first run", func(t *testing.T) {
+		assert.Equal(t, time.Date(2026, 1, 1, 6, 30, 0, 0, time.UTC), sched.slot(time.Time{}, now))
+	})
+	t.Run("next slot", func(t *testing.T) {
+		last := time.Date(2026, 1, 1, 4, 30, 0, 0, time.UTC)
+		assert.Equal(t, time.Date(2026, 1, 1, 6, 30, 0, 0, time.UTC), sched.slot(last, now))
+	})
+	t.Run("missed slots", func(t *testing.T) {
+		last := time.Date(2025, 12, 31, 20, 30, 0, 0, time.UTC)
+		assert.Equal(t, time.Date(2026, 1, 1, 4, 30, 0, 0, time.UTC), sched.slot(last, now))
+	})
+}
+
+func TestIntervalSchedule_slot(t *testing.T) {
+	sched := intervalSchedule(10 * time.Minute)
+	now := time.Date(2026, 1, 1, 5, 0, 0, 0, time.UTC)
+
+	t.Run("first run", func(t *testing.T) {
+		assert.Equal(t, now, sched.slot(time.Time{}, now))
+	})
+	t.Run("next slot", func(t *testing.T) {
+		last := now.Add(-3 * time.Minute)
+		assert.Equal(t, now.Add(7*time.Minute), sched.slot(last, now))
+	})
+	t.Run("missed slots", func(t *testing.T) {
+		last := now.Add(-35 * time.Minute)
+		assert.Equal(t, now.Add(-5*time.Minute), sched.slot(last, now))
+	})
+}
@@ -0,0 +1,58 @@
+// Copyright © 2026 by PACE Telematics GmbH. All rights reserved.
+
+package routine
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	exponential "github.com/jpillora/backoff"
+
+	"github.com/pace/bricks/maintenance/log"
+)
+
+const workerMaxBackoff = 10 * time.Minute
+
+type routineWithWorkers struct {
+	Name    string
+	Routine func(context.Context)
+	Workers int
+}
+
+func (r *routineWithWorkers) Run(ctx context.Context) {
+	num := ctx.Value(ctxNumKey{}).(int64)
+
+	var wg sync.WaitGroup
+	for i := 0; i < r.Workers; i++ {
+		logger := log.Ctx(ctx).With().Int("worker", i).Logger()
+		workerCtx := logger.WithContext(ctx)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.runWorker(workerCtx, num)
+		}()
+	}
+	wg.Wait()
+}
+
+// Runs a single worker until it returns regularly or the context is done. If
+// the worker panics, it is restarted using exponential backoff. The backoff is
+// reset if the worker ran stable for at least the maximum backoff before.
+func (r *routineWithWorkers) runWorker(ctx context.Context, num int64) {
+	backoff := combinedExponentialBackoff{
+		"routine": &exponential.Backoff{Min: cfg.WorkerRestartBackoff, Max: workerMaxBackoff},
+	}
+	for {
+		start := time.Now()
+		if !runInstance(ctx, ctx, num, r.Routine) {
+			return
+		}
+		if time.Since(start) >= workerMaxBackoff {
+			backoff.ResetAll()
+		}
+		if !waitForRestart(ctx, r.Name, backoff.Duration("routine")) {
+			return
+		}
+	}
+}